@@ -0,0 +1,204 @@
+// Package attribution measures how much of a codebase came from commits with
+// AI signals: it blames every file at a revision and joins each line's
+// originating commit with that commit's scan findings.
+package attribution
+
+import (
+	"math"
+	"path"
+	"sort"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/gitops"
+	"github.com/chaoss/ai-detection-action/internal/glob"
+	"github.com/chaoss/ai-detection-action/scan"
+)
+
+// levels are the confidence levels lines are counted at.
+var levels = []detection.Confidence{
+	detection.ConfidenceLow,
+	detection.ConfidenceMedium,
+	detection.ConfidenceHigh,
+}
+
+// Lines counts the lines of a file or directory. AI counts, for each
+// confidence level, the lines last changed by a commit whose strongest AI
+// finding is at that level or above, so a high-confidence line counts at all
+// three levels. Percent is AI as a percentage of Total.
+type Lines struct {
+	Total   int                `json:"total"`
+	AI      map[string]int     `json:"ai"`
+	Percent map[string]float64 `json:"percent"`
+}
+
+// Entry is the line count of one file or directory.
+type Entry struct {
+	Path string `json:"path"`
+	Lines
+}
+
+// Report is the attribution of a repository at one commit. Directories
+// include the lines of everything below them.
+type Report struct {
+	Commit      string  `json:"commit"`
+	Total       Lines   `json:"total"`
+	Directories []Entry `json:"directories"`
+	Files       []Entry `json:"files"`
+}
+
+// Options selects what to attribute.
+type Options struct {
+	// Revision is the commit whose files are blamed; empty means HEAD.
+	Revision string
+
+	// Include and Exclude are path globs, with ** matching any number of
+	// directories. When Include is set only matching files are counted, and
+	// files matching Exclude never are.
+	Include []string
+	Exclude []string
+}
+
+// Attribute blames the files at opts.Revision and classifies each line by the
+// findings scanner reports for the commit that last changed it. Only the
+// commits some line is blamed on are scanned, and a scanner with a Cache
+// makes repeated runs cheap.
+func Attribute(repoPath string, opts Options, scanner *scan.Scanner) (Report, error) {
+	hash, err := gitops.ResolveRevision(repoPath, opts.Revision)
+	if err != nil {
+		return Report{}, err
+	}
+
+	keep := func(p string) bool {
+		if len(opts.Include) > 0 && !glob.MatchAny(opts.Include, p) {
+			return false
+		}
+		return !glob.MatchAny(opts.Exclude, p)
+	}
+
+	// Lines hold an index into commits rather than the hash, since most
+	// lines share their commit with many others.
+	type blamedFile struct {
+		path  string
+		lines []int
+	}
+	var files []blamedFile
+	var commits []string
+	index := map[string]int{}
+	for blame, err := range gitops.BlameFiles(repoPath, hash, keep) {
+		if err != nil {
+			return Report{}, err
+		}
+		if len(blame.Commits) == 0 {
+			continue
+		}
+		file := blamedFile{path: blame.Path, lines: make([]int, len(blame.Commits))}
+		for i, commit := range blame.Commits {
+			n, ok := index[commit]
+			if !ok {
+				n = len(commits)
+				index[commit] = n
+				commits = append(commits, commit)
+			}
+			file.lines[i] = n
+		}
+		files = append(files, file)
+	}
+
+	strongest := make([]detection.Confidence, len(commits))
+	for result, err := range scanner.StreamHashes(repoPath, commits) {
+		if err != nil {
+			return Report{}, err
+		}
+		strongest[index[result.Hash]] = strongestAI(result)
+	}
+
+	report := Report{Commit: hash, Total: newLines()}
+	dirs := map[string]*Lines{}
+	for _, f := range files {
+		file := newLines()
+		for _, n := range f.lines {
+			file.count(strongest[n])
+		}
+		report.Files = append(report.Files, Entry{Path: f.path, Lines: file})
+		report.Total.merge(file)
+
+		for dir := path.Dir(f.path); dir != "."; dir = path.Dir(dir) {
+			if dirs[dir] == nil {
+				l := newLines()
+				dirs[dir] = &l
+			}
+			dirs[dir].merge(file)
+		}
+	}
+
+	for dir, l := range dirs {
+		report.Directories = append(report.Directories, Entry{Path: dir, Lines: *l})
+	}
+	sort.Slice(report.Directories, func(i, j int) bool {
+		return report.Directories[i].Path < report.Directories[j].Path
+	})
+	sort.Slice(report.Files, func(i, j int) bool {
+		return report.Files[i].Path < report.Files[j].Path
+	})
+
+	report.Total.finish()
+	for i := range report.Directories {
+		report.Directories[i].finish()
+	}
+	for i := range report.Files {
+		report.Files[i].finish()
+	}
+	return report, nil
+}
+
+// strongestAI returns the highest confidence among the AI findings of r, or
+// zero if it has none. Negative disclosures do not count.
+func strongestAI(r scan.CommitResult) detection.Confidence {
+	var strongest detection.Confidence
+	for _, findings := range [][]detection.Finding{r.Findings, r.Recorded} {
+		for _, f := range findings {
+			if f.IndicatesAI() {
+				strongest = max(strongest, f.Confidence)
+			}
+		}
+	}
+	return strongest
+}
+
+func newLines() Lines {
+	l := Lines{AI: map[string]int{}, Percent: map[string]float64{}}
+	for _, level := range levels {
+		l.AI[level.String()] = 0
+	}
+	return l
+}
+
+// count adds one line last changed by a commit whose strongest AI finding
+// has confidence c, zero for none.
+func (l *Lines) count(c detection.Confidence) {
+	l.Total++
+	for _, level := range levels {
+		if c >= level {
+			l.AI[level.String()]++
+		}
+	}
+}
+
+func (l *Lines) merge(other Lines) {
+	l.Total += other.Total
+	for k, v := range other.AI {
+		l.AI[k] += v
+	}
+}
+
+// finish fills in Percent, rounded to one decimal place.
+func (l *Lines) finish() {
+	for _, level := range levels {
+		name := level.String()
+		if l.Total == 0 {
+			l.Percent[name] = 0
+			continue
+		}
+		l.Percent[name] = math.Round(1000*float64(l.AI[name])/float64(l.Total)) / 10
+	}
+}
@@ -0,0 +1,204 @@
+package attribution
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/detection/coauthor"
+	"github.com/chaoss/ai-detection-action/detection/message"
+	"github.com/chaoss/ai-detection-action/scan"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// initRepo commits, in order:
+//
+//	human:  README.md (4 lines), src/app.go (2 lines)
+//	Claude: rewrites line 2 of README.md, adds src/ai/gen.go (3 lines)
+//	aider:  adds src/ai/helper.go (1 line)
+func initRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	commits := []struct {
+		msg   string
+		files map[string]string
+	}{
+		{"initial commit", map[string]string{
+			"README.md":  "one\ntwo\nthree\nfour\n",
+			"src/app.go": "package app\nfunc Run() {}\n",
+		}},
+		{"add generator\n\nCo-Authored-By: Claude <noreply@anthropic.com>", map[string]string{
+			"README.md":     "one\nTWO\nthree\nfour\n",
+			"src/ai/gen.go": "package ai\n\nfunc Gen() {}\n",
+		}},
+		{"aider: add helper", map[string]string{
+			"src/ai/helper.go": "package ai\n",
+		}},
+	}
+	for i, c := range commits {
+		for name, contents := range c.files {
+			path := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				t.Fatalf("mkdir: %v", err)
+			}
+			if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			if _, err := wt.Add(name); err != nil {
+				t.Fatalf("add: %v", err)
+			}
+		}
+		sig := &object.Signature{Name: "Test", Email: "human@example.com", When: base.Add(time.Duration(i) * time.Minute)}
+		if _, err := wt.Commit(c.msg, &git.CommitOptions{Author: sig, Committer: sig}); err != nil {
+			t.Fatalf("commit: %v", err)
+		}
+	}
+	return dir
+}
+
+func scanner() *scan.Scanner {
+	return &scan.Scanner{Detectors: []detection.Detector{&coauthor.Detector{}, &message.Detector{}}}
+}
+
+func TestAttribute(t *testing.T) {
+	dir := initRepo(t)
+
+	report, err := Attribute(dir, Options{}, scanner())
+	if err != nil {
+		t.Fatalf("Attribute: %v", err)
+	}
+	if len(report.Commit) != 40 {
+		t.Errorf("commit = %q, want a full hash", report.Commit)
+	}
+
+	want := map[string][4]int{ // total, low, medium, high
+		"README.md":        {4, 1, 1, 1},
+		"src/app.go":       {2, 0, 0, 0},
+		"src/ai/gen.go":    {3, 3, 3, 3},
+		"src/ai/helper.go": {1, 1, 1, 0},
+		"src":              {6, 4, 4, 3},
+		"src/ai":           {4, 4, 4, 3},
+	}
+	got := map[string]Lines{}
+	for _, e := range append(report.Files, report.Directories...) {
+		got[e.Path] = e.Lines
+	}
+	for path, w := range want {
+		l, ok := got[path]
+		if !ok {
+			t.Errorf("%s missing from report", path)
+			continue
+		}
+		if l.Total != w[0] || l.AI["low"] != w[1] || l.AI["medium"] != w[2] || l.AI["high"] != w[3] {
+			t.Errorf("%s = %d lines, AI %v; want %v", path, l.Total, l.AI, w)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("report has %d entries, want %d", len(got), len(want))
+	}
+
+	if report.Total.Total != 10 || report.Total.AI["low"] != 5 {
+		t.Errorf("total = %d lines, %d AI; want 10 and 5", report.Total.Total, report.Total.AI["low"])
+	}
+	if p := report.Total.Percent["low"]; p != 50 {
+		t.Errorf("total low percent = %v, want 50", p)
+	}
+	if p := got["src/ai"].Percent["high"]; p != 75 {
+		t.Errorf("src/ai high percent = %v, want 75", p)
+	}
+}
+
+// hashDetector records the commits it is run on.
+type hashDetector struct{ hashes []string }
+
+func (d *hashDetector) Name() string { return "hashes" }
+
+func (d *hashDetector) Detect(input detection.Input) []detection.Finding {
+	d.hashes = append(d.hashes, input.CommitHash)
+	return nil
+}
+
+func TestAttributeScansBlamedCommits(t *testing.T) {
+	dir := initRepo(t)
+
+	// A human rewrites the only line the aider commit still owns.
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "src/ai/helper.go"), []byte("package helper\n"), 0o644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := wt.Add("src/ai/helper.go"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	sig := &object.Signature{Name: "Test", Email: "human@example.com", When: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+	if _, err := wt.Commit("rename package", &git.CommitOptions{Author: sig, Committer: sig}); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	d := &hashDetector{}
+	report, err := Attribute(dir, Options{}, &scan.Scanner{Detectors: []detection.Detector{&message.Detector{}, d}})
+	if err != nil {
+		t.Fatalf("Attribute: %v", err)
+	}
+	if len(d.hashes) != 3 {
+		t.Errorf("scanned %d commits, want the 3 that own a line", len(d.hashes))
+	}
+	if report.Total.Total != 10 || report.Total.AI["low"] != 0 {
+		t.Errorf("total = %d lines, %d AI; want 10 and none from the aider commit", report.Total.Total, report.Total.AI["low"])
+	}
+}
+
+func TestAttributeFilters(t *testing.T) {
+	dir := initRepo(t)
+
+	report, err := Attribute(dir, Options{Include: []string{"src/**"}, Exclude: []string{"**/helper.go"}}, scanner())
+	if err != nil {
+		t.Fatalf("Attribute: %v", err)
+	}
+	var paths []string
+	for _, f := range report.Files {
+		paths = append(paths, f.Path)
+	}
+	if len(paths) != 2 || paths[0] != "src/ai/gen.go" || paths[1] != "src/app.go" {
+		t.Errorf("files = %v, want src/ai/gen.go and src/app.go", paths)
+	}
+	if report.Total.Total != 5 || report.Total.AI["medium"] != 3 {
+		t.Errorf("total = %d lines, %d medium; want 5 and 3", report.Total.Total, report.Total.AI["medium"])
+	}
+}
+
+func TestAttributeRevision(t *testing.T) {
+	dir := initRepo(t)
+
+	report, err := Attribute(dir, Options{Revision: "HEAD~2"}, scanner())
+	if err != nil {
+		t.Fatalf("Attribute: %v", err)
+	}
+	if report.Total.Total != 6 || report.Total.AI["low"] != 0 {
+		t.Errorf("at HEAD~2: %d lines, %d AI; want 6 and 0", report.Total.Total, report.Total.AI["low"])
+	}
+
+	if _, err := Attribute(dir, Options{Revision: "nope"}, scanner()); err == nil {
+		t.Error("expected error for unknown revision")
+	}
+}
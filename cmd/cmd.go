@@ -1,16 +1,27 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"os"
+	"runtime"
+	"time"
 
+	"github.com/chaoss/ai-detection-action/attribution"
 	"github.com/chaoss/ai-detection-action/detection"
 	"github.com/chaoss/ai-detection-action/detection/coauthor"
 	"github.com/chaoss/ai-detection-action/detection/committer"
+	"github.com/chaoss/ai-detection-action/detection/disclosure"
+	"github.com/chaoss/ai-detection-action/detection/files"
+	"github.com/chaoss/ai-detection-action/detection/gitai"
 	"github.com/chaoss/ai-detection-action/detection/message"
+	"github.com/chaoss/ai-detection-action/detection/refs"
+	"github.com/chaoss/ai-detection-action/detection/rules"
 	"github.com/chaoss/ai-detection-action/detection/toolmention"
+	"github.com/chaoss/ai-detection-action/gitops"
 	"github.com/chaoss/ai-detection-action/output"
+	"github.com/chaoss/ai-detection-action/profile"
 	"github.com/chaoss/ai-detection-action/scan"
 	"github.com/spf13/cobra"
 )
@@ -24,13 +35,36 @@ const (
 	ExitError = 2
 )
 
-func allDetectors() []detection.Detector {
-	return []detection.Detector{
+// notesBatchSize is how many notes scan --write-notes holds before writing
+// them as one commit on the notes ref.
+var notesBatchSize = 1000
+
+// allDetectors returns the built-in detectors, plus a rules detector when
+// rulesPath names a custom rules file. headRef is passed to the refs
+// detector.
+func allDetectors(rulesPath, headRef string) ([]detection.Detector, error) {
+	detectors := []detection.Detector{
 		&committer.Detector{},
 		&coauthor.Detector{},
 		&message.Detector{},
 		&toolmention.Detector{},
+		&disclosure.Detector{},
+		&files.Detector{},
+	}
+
+	if rulesPath != "" {
+		custom, err := rules.Load(rulesPath)
+		if err != nil {
+			return nil, err
+		}
+		detectors = append(detectors, custom)
 	}
+
+	// The volatile detectors go last. The scanner runs them after the others
+	// whatever their place, since results taken from a cache or a note get
+	// their findings appended, so listing them here too keeps the detector
+	// order of reports and metadata the order findings appear in.
+	return append(detectors, &gitai.Detector{}, &refs.Detector{HeadRef: headRef}), nil
 }
 
 // Run is the main entry point for the CLI. Returns an exit code.
@@ -48,6 +82,10 @@ func Run(args []string, stdout, stderr io.Writer) int {
 
 	rootCmd.AddCommand(scanCommand(stdout, stderr, &exitCode))
 	rootCmd.AddCommand(textCommand(stdout, stderr, &exitCode))
+	rootCmd.AddCommand(profileCommand(stdout, stderr, &exitCode))
+	rootCmd.AddCommand(attributionCommand(stdout, stderr, &exitCode))
+	rootCmd.AddCommand(diffCommand(stdout, stderr, &exitCode))
+	rootCmd.AddCommand(mergeCommand(stdout, stderr, &exitCode))
 	rootCmd.AddCommand(versionCommand(stdout, &exitCode))
 
 	rootCmd.SetArgs(args)
@@ -61,14 +99,23 @@ func Run(args []string, stdout, stderr io.Writer) int {
 
 func scanCommand(stdout, stderr io.Writer, exitCode *int) *cobra.Command {
 	var rangeFlag string
+	var baseFlag string
 	var formatFlag string
 	var minConfFlag string
+	var rulesFlag string
+	var jobsFlag int
+	var cacheFlag string
+	var writeNotesFlag string
+	var readNotesFlag string
+	var headRefFlag string
+	var rowsFlag string
+	var templateFlag string
 
 	cmd := &cobra.Command{
 		Use:   "scan [repo-path]",
 		Short: "Scan commits for AI signals",
 		Args:  cobra.MaximumNArgs(1),
-		RunE: func(_ *cobra.Command, args []string) error {
+		RunE: func(_ *cobra.Command, args []string) (err error) {
 			repoPath := "."
 			if len(args) > 0 {
 				repoPath = args[0]
@@ -81,53 +128,174 @@ func scanCommand(stdout, stderr io.Writer, exitCode *int) *cobra.Command {
 				return err
 			}
 
-			detectors := allDetectors()
-			report, err := scan.ScanCommitRange(repoPath, rangeFlag, detectors)
+			detectors, err := allDetectors(rulesFlag, headRefFlag)
 			if err != nil {
 				fmt.Fprintf(stderr, "error: %v\n", err)
 				*exitCode = ExitError
 				return err
 			}
 
-			report = filterReport(report, minConf)
+			if baseFlag != "" {
+				if rangeFlag != "" {
+					err := fmt.Errorf("--base and --range cannot be used together")
+					fmt.Fprintln(stderr, err)
+					*exitCode = ExitError
+					return err
+				}
+				rangeFlag, err = gitops.BaseRange(repoPath, baseFlag)
+				if err != nil {
+					fmt.Fprintf(stderr, "error: %v\n", err)
+					*exitCode = ExitError
+					return err
+				}
+			}
 
-			switch formatFlag {
-			case "json":
-				if err := output.FormatJSON(stdout, report); err != nil {
+			writer, err := scanWriter(stdout, formatFlag, rowsFlag, templateFlag)
+			if err != nil {
+				fmt.Fprintln(stderr, err)
+				*exitCode = ExitError
+				return err
+			}
+
+			meta, err := scan.NewMetadata(repoPath, rangeFlag, detectors)
+			if err != nil {
+				fmt.Fprintf(stderr, "error: %v\n", err)
+				*exitCode = ExitError
+				return err
+			}
+			meta.ToolVersion = Version
+			meta.Filters.MinConfidence = minConf.String()
+
+			// Results are written as they are scanned and the summary is
+			// built alongside, so large histories never sit in memory.
+			jobs := jobsFlag
+			if jobs <= 0 {
+				jobs = runtime.NumCPU()
+			}
+			scanner := &scan.Scanner{Detectors: detectors, Jobs: jobs, NotesRef: readNotesFlag}
+			if cacheFlag != "" {
+				scanner.Cache, err = scan.OpenCache(cacheFlag, detectors)
+				if err != nil {
 					fmt.Fprintf(stderr, "error: %v\n", err)
 					*exitCode = ExitError
 					return err
 				}
-			case "text":
-				if err := output.FormatText(stdout, report); err != nil {
+			}
+
+			// Notes record the unfiltered findings, so that any later
+			// --min-confidence can be applied to them. They are written in
+			// batches so that a long history is not held in memory, and the
+			// last batch is written even if the scan fails, so that the
+			// commits scanned so far keep their results.
+			notes := map[string][]byte{}
+			flushNotes := func() error {
+				defer clear(notes)
+				return gitops.WriteNotes(repoPath, writeNotesFlag, notes, "Notes added by 'ai-detection scan'\n")
+			}
+			defer func() {
+				if writeNotesFlag == "" {
+					return
+				}
+				if flushErr := flushNotes(); flushErr != nil {
+					fmt.Fprintf(stderr, "error: %v\n", flushErr)
+					*exitCode = ExitError
+					err = errors.Join(err, flushErr)
+				}
+			}()
+
+			summary := scan.NewSummary()
+			for result, err := range scanner.Stream(repoPath, rangeFlag) {
+				if err != nil {
 					fmt.Fprintf(stderr, "error: %v\n", err)
 					*exitCode = ExitError
 					return err
 				}
-			default:
-				err := fmt.Errorf("unknown format: %s", formatFlag)
-				fmt.Fprintln(stderr, err)
+				if writeNotesFlag != "" {
+					notes[result.Hash], err = scan.NewNote(result, detectors).Marshal()
+					if err == nil && len(notes) >= notesBatchSize {
+						err = flushNotes()
+					}
+					if err != nil {
+						fmt.Fprintf(stderr, "error: %v\n", err)
+						*exitCode = ExitError
+						return err
+					}
+				}
+				result = filterCommit(result, minConf)
+				summary.Add(result)
+				if err := writer.WriteCommit(result); err != nil {
+					fmt.Fprintf(stderr, "error: %v\n", err)
+					*exitCode = ExitError
+					return err
+				}
+			}
+			meta.FinishedAt = time.Now().UTC()
+			if err := writer.Close(summary, &meta); err != nil {
+				fmt.Fprintf(stderr, "error: %v\n", err)
 				*exitCode = ExitError
 				return err
 			}
 
-			if report.Summary.AICommits > 0 {
+			if summary.AICommits > 0 {
 				*exitCode = ExitAI
 			}
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVar(&rangeFlag, "range", "", "commit range in BASE..HEAD format")
-	cmd.Flags().StringVar(&formatFlag, "format", "text", "output format: json or text")
+	cmd.Flags().StringVar(&rangeFlag, "range", "", "commits to scan in git revision range syntax, e.g. BASE..HEAD, A...B or \"main ^v1.0\"")
+	cmd.Flags().StringVar(&baseFlag, "base", "", "scan only commits on HEAD that are not on this branch, from their merge base; \"auto\" uses the remote default branch")
+	cmd.Flags().StringVar(&formatFlag, "format", "text", "output format: json, ndjson, text, sarif, markdown, csv, tsv or template")
+	cmd.Flags().StringVar(&rowsFlag, "rows", "findings", "what a csv or tsv row stands for: findings or commits")
+	cmd.Flags().StringVar(&templateFlag, "template", "", "path to a Go text/template file that --format=template renders the report with")
 	cmd.Flags().StringVar(&minConfFlag, "min-confidence", "low", "minimum confidence level: low, medium, high (or 1, 2, 3)")
+	cmd.Flags().StringVar(&rulesFlag, "rules", "", "path to a YAML or JSON file of custom detection rules")
+	cmd.Flags().IntVar(&jobsFlag, "jobs", 0, "number of commits to scan in parallel (0 = number of CPUs)")
+	cmd.Flags().StringVar(&writeNotesFlag, "write-notes", "", "store each commit's findings as a JSON git note under this notes ref")
+	cmd.Flags().Lookup("write-notes").NoOptDefVal = scan.DefaultNotesRef
+	cmd.Flags().StringVar(&readNotesFlag, "read-notes", "", "reuse findings stored by --write-notes under this notes ref, and show those a rewritten commit lost")
+	cmd.Flags().Lookup("read-notes").NoOptDefVal = scan.DefaultNotesRef
+	cmd.Flags().StringVar(&cacheFlag, "cache-dir", "", "directory of cached commit results; commits already scanned with the same detectors are not scanned again")
+	cmd.Flags().StringVar(&headRefFlag, "head-ref", "", "branch HEAD was checked out from, for detached CI checkouts, e.g. $GITHUB_HEAD_REF")
 
 	return cmd
 }
 
+// scanWriter returns the ReportWriter for the scan command's --format,
+// --rows and --template flags.
+func scanWriter(w io.Writer, format, rowsFlag, templateFlag string) (output.ReportWriter, error) {
+	rows, err := output.ParseCSVRows(rowsFlag)
+	if err != nil {
+		return nil, err
+	}
+	if templateFlag != "" && format != "template" {
+		return nil, fmt.Errorf("--template needs --format=template")
+	}
+	switch {
+	case rows == output.RowsFindings && format == "template":
+		if templateFlag == "" {
+			return nil, fmt.Errorf("--format=template needs --template=FILE")
+		}
+		tmpl, err := output.LoadTemplate(templateFlag)
+		if err != nil {
+			return nil, err
+		}
+		return output.NewTemplateWriter(w, tmpl), nil
+	case rows == output.RowsFindings:
+		return output.NewReportWriter(w, format)
+	case format == "csv":
+		return output.NewCSVWriter(w, ',', rows), nil
+	case format == "tsv":
+		return output.NewCSVWriter(w, '\t', rows), nil
+	default:
+		return nil, fmt.Errorf("--rows=%s needs --format=csv or --format=tsv", rowsFlag)
+	}
+}
+
 func textCommand(stdout, stderr io.Writer, exitCode *int) *cobra.Command {
 	var formatFlag string
 	var inputFlag string
+	var rulesFlag string
 
 	cmd := &cobra.Command{
 		Use:   "text",
@@ -147,7 +315,13 @@ func textCommand(stdout, stderr io.Writer, exitCode *int) *cobra.Command {
 				return err
 			}
 
-			detectors := allDetectors()
+			detectors, err := allDetectors(rulesFlag, "")
+			if err != nil {
+				fmt.Fprintf(stderr, "error: %v\n", err)
+				*exitCode = ExitError
+				return err
+			}
+
 			findings := scan.ScanText(string(textBytes), detectors)
 
 			switch formatFlag {
@@ -163,6 +337,18 @@ func textCommand(stdout, stderr io.Writer, exitCode *int) *cobra.Command {
 					*exitCode = ExitError
 					return err
 				}
+			case "sarif":
+				if err := output.FormatSARIFFindings(stdout, findings); err != nil {
+					fmt.Fprintf(stderr, "error: %v\n", err)
+					*exitCode = ExitError
+					return err
+				}
+			case "markdown":
+				if err := output.FormatMarkdownFindings(stdout, findings); err != nil {
+					fmt.Fprintf(stderr, "error: %v\n", err)
+					*exitCode = ExitError
+					return err
+				}
 			default:
 				err := fmt.Errorf("unknown format: %s", formatFlag)
 				fmt.Fprintln(stderr, err)
@@ -170,15 +356,258 @@ func textCommand(stdout, stderr io.Writer, exitCode *int) *cobra.Command {
 				return err
 			}
 
-			if len(findings) > 0 {
+			if detection.AnyIndicatesAI(findings) {
 				*exitCode = ExitAI
 			}
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVar(&formatFlag, "format", "text", "output format: json or text")
+	cmd.Flags().StringVar(&formatFlag, "format", "text", "output format: json, text, sarif or markdown")
 	cmd.Flags().StringVar(&inputFlag, "input", "-", "input file path, or - for stdin")
+	cmd.Flags().StringVar(&rulesFlag, "rules", "", "path to a YAML or JSON file of custom detection rules")
+
+	return cmd
+}
+
+func profileCommand(stdout, stderr io.Writer, exitCode *int) *cobra.Command {
+	var formatFlag string
+
+	cmd := &cobra.Command{
+		Use:   "profile [repo-path]",
+		Short: "Inventory the AI tool setup of a repository at HEAD",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			repoPath := "."
+			if len(args) > 0 {
+				repoPath = args[0]
+			}
+
+			report, err := profile.Profile(repoPath)
+			if err != nil {
+				fmt.Fprintf(stderr, "error: %v\n", err)
+				*exitCode = ExitError
+				return err
+			}
+
+			switch formatFlag {
+			case "json":
+				if err := output.FormatProfileJSON(stdout, report); err != nil {
+					fmt.Fprintf(stderr, "error: %v\n", err)
+					*exitCode = ExitError
+					return err
+				}
+			case "text":
+				if err := output.FormatProfileText(stdout, report); err != nil {
+					fmt.Fprintf(stderr, "error: %v\n", err)
+					*exitCode = ExitError
+					return err
+				}
+			default:
+				err := fmt.Errorf("unknown format: %s", formatFlag)
+				fmt.Fprintln(stderr, err)
+				*exitCode = ExitError
+				return err
+			}
+
+			if len(report.Tools) > 0 {
+				*exitCode = ExitAI
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&formatFlag, "format", "text", "output format: json or text")
+
+	return cmd
+}
+
+func attributionCommand(stdout, stderr io.Writer, exitCode *int) *cobra.Command {
+	var revFlag string
+	var includeFlag []string
+	var excludeFlag []string
+	var formatFlag string
+	var rulesFlag string
+	var jobsFlag int
+	var cacheFlag string
+
+	cmd := &cobra.Command{
+		Use:   "attribution [repo-path]",
+		Short: "Measure the share of lines last changed by commits with AI signals",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			repoPath := "."
+			if len(args) > 0 {
+				repoPath = args[0]
+			}
+
+			if formatFlag != "json" && formatFlag != "text" {
+				err := fmt.Errorf("unknown format: %s", formatFlag)
+				fmt.Fprintln(stderr, err)
+				*exitCode = ExitError
+				return err
+			}
+
+			detectors, err := allDetectors(rulesFlag, "")
+			if err != nil {
+				fmt.Fprintf(stderr, "error: %v\n", err)
+				*exitCode = ExitError
+				return err
+			}
+
+			jobs := jobsFlag
+			if jobs <= 0 {
+				jobs = runtime.NumCPU()
+			}
+			scanner := &scan.Scanner{Detectors: detectors, Jobs: jobs}
+			if cacheFlag != "" {
+				scanner.Cache, err = scan.OpenCache(cacheFlag, detectors)
+				if err != nil {
+					fmt.Fprintf(stderr, "error: %v\n", err)
+					*exitCode = ExitError
+					return err
+				}
+			}
+
+			opts := attribution.Options{Revision: revFlag, Include: includeFlag, Exclude: excludeFlag}
+			report, err := attribution.Attribute(repoPath, opts, scanner)
+			if err != nil {
+				fmt.Fprintf(stderr, "error: %v\n", err)
+				*exitCode = ExitError
+				return err
+			}
+
+			if formatFlag == "json" {
+				err = output.FormatAttributionJSON(stdout, report)
+			} else {
+				err = output.FormatAttributionText(stdout, report)
+			}
+			if err != nil {
+				fmt.Fprintf(stderr, "error: %v\n", err)
+				*exitCode = ExitError
+				return err
+			}
+
+			if report.Total.AI[detection.ConfidenceLow.String()] > 0 {
+				*exitCode = ExitAI
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&revFlag, "rev", "HEAD", "revision whose files are attributed")
+	cmd.Flags().StringArrayVar(&includeFlag, "include", nil, "only count files matching this glob, e.g. \"src/**\" (repeatable)")
+	cmd.Flags().StringArrayVar(&excludeFlag, "exclude", nil, "skip files matching this glob, e.g. \"**/*_test.go\" (repeatable)")
+	cmd.Flags().StringVar(&formatFlag, "format", "text", "output format: json or text")
+	cmd.Flags().StringVar(&rulesFlag, "rules", "", "path to a YAML or JSON file of custom detection rules")
+	cmd.Flags().IntVar(&jobsFlag, "jobs", 0, "number of commits to scan in parallel (0 = number of CPUs)")
+	cmd.Flags().StringVar(&cacheFlag, "cache-dir", "", "directory of cached commit results; commits already scanned with the same detectors are not scanned again")
+
+	return cmd
+}
+
+func diffCommand(stdout, stderr io.Writer, exitCode *int) *cobra.Command {
+	var formatFlag string
+
+	cmd := &cobra.Command{
+		Use:   "diff old.json new.json",
+		Short: "Compare two JSON scan reports and list commits whose findings changed",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if formatFlag != "json" && formatFlag != "text" {
+				err := fmt.Errorf("unknown format: %s", formatFlag)
+				fmt.Fprintln(stderr, err)
+				*exitCode = ExitError
+				return err
+			}
+
+			reports := make([]scan.Report, len(args))
+			for i, path := range args {
+				report, err := scan.LoadReport(path)
+				if err != nil {
+					fmt.Fprintf(stderr, "error: %v\n", err)
+					*exitCode = ExitError
+					return err
+				}
+				reports[i] = report
+			}
+
+			diff := scan.Diff(reports[0], reports[1])
+			var err error
+			if formatFlag == "json" {
+				err = output.FormatDiffJSON(stdout, diff)
+			} else {
+				err = output.FormatDiffText(stdout, diff)
+			}
+			if err != nil {
+				fmt.Fprintf(stderr, "error: %v\n", err)
+				*exitCode = ExitError
+				return err
+			}
+
+			// Regressions fail a CI gate the same way AI findings do.
+			if diff.Regressions > 0 {
+				*exitCode = ExitAI
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&formatFlag, "format", "text", "output format: json or text")
+
+	return cmd
+}
+
+func mergeCommand(stdout, stderr io.Writer, exitCode *int) *cobra.Command {
+	var formatFlag string
+
+	cmd := &cobra.Command{
+		Use:   "merge report.json...",
+		Short: "Combine JSON scan reports, such as of sharded scans, into one",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if formatFlag != "json" && formatFlag != "text" && formatFlag != "markdown" {
+				err := fmt.Errorf("unknown format: %s", formatFlag)
+				fmt.Fprintln(stderr, err)
+				*exitCode = ExitError
+				return err
+			}
+
+			reports := make([]scan.Report, len(args))
+			for i, path := range args {
+				report, err := scan.LoadReport(path)
+				if err != nil {
+					fmt.Fprintf(stderr, "error: %v\n", err)
+					*exitCode = ExitError
+					return err
+				}
+				reports[i] = report
+			}
+
+			merged := scan.Merge(reports...)
+			var err error
+			switch formatFlag {
+			case "json":
+				err = output.FormatJSON(stdout, merged)
+			case "markdown":
+				err = output.FormatMarkdown(stdout, merged)
+			default:
+				err = output.FormatText(stdout, merged)
+			}
+			if err != nil {
+				fmt.Fprintf(stderr, "error: %v\n", err)
+				*exitCode = ExitError
+				return err
+			}
+
+			if merged.Summary.AICommits > 0 {
+				*exitCode = ExitAI
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&formatFlag, "format", "json", "output format: json, text or markdown")
 
 	return cmd
 }
@@ -194,38 +623,24 @@ func versionCommand(stdout io.Writer, exitCode *int) *cobra.Command {
 	}
 }
 
-func filterReport(report scan.Report, minConf detection.Confidence) scan.Report {
+// filterCommit drops the findings of cr below minConf.
+func filterCommit(cr scan.CommitResult, minConf detection.Confidence) scan.CommitResult {
 	if minConf <= detection.ConfidenceLow {
-		return report
+		return cr
 	}
 
-	filtered := scan.Report{
-		Commits: make([]scan.CommitResult, 0, len(report.Commits)),
-		Summary: scan.Summary{
-			TotalCommits: report.Summary.TotalCommits,
-			ToolCounts:   map[string]int{},
-			ByConfidence: map[string]int{},
-		},
-	}
-
-	for _, cr := range report.Commits {
-		var kept []detection.Finding
-		for _, f := range cr.Findings {
-			if f.Confidence >= minConf {
-				kept = append(kept, f)
-			}
-		}
-		result := scan.CommitResult{Hash: cr.Hash, Findings: kept}
-		filtered.Commits = append(filtered.Commits, result)
+	result := cr
+	result.Findings = filterFindings(cr.Findings, minConf)
+	result.Recorded = filterFindings(cr.Recorded, minConf)
+	return result
+}
 
-		if len(kept) > 0 {
-			filtered.Summary.AICommits++
-		}
-		for _, f := range kept {
-			filtered.Summary.ToolCounts[f.Tool]++
-			filtered.Summary.ByConfidence[f.Confidence.String()]++
+func filterFindings(findings []detection.Finding, minConf detection.Confidence) []detection.Finding {
+	var kept []detection.Finding
+	for _, f := range findings {
+		if f.Confidence >= minConf {
+			kept = append(kept, f)
 		}
 	}
-
-	return filtered
+	return kept
 }
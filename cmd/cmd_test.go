@@ -2,16 +2,28 @@ package cmd
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/chaoss/ai-detection-action/attribution"
 	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/gitops"
+	"github.com/chaoss/ai-detection-action/output"
+	"github.com/chaoss/ai-detection-action/profile"
 	"github.com/chaoss/ai-detection-action/scan"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
@@ -127,6 +139,204 @@ func TestRunScanJSON(t *testing.T) {
 	if report.Summary.AICommits == 0 {
 		t.Error("expected AI commits in report")
 	}
+	if report.SchemaVersion != scan.ReportSchemaVersion {
+		t.Errorf("schema_version = %d, want %d", report.SchemaVersion, scan.ReportSchemaVersion)
+	}
+	meta := report.Metadata
+	if meta == nil {
+		t.Fatal("report has no metadata")
+	}
+	if meta.ToolVersion != Version || meta.Filters.MinConfidence != "low" {
+		t.Errorf("tool_version = %q, min_confidence = %q", meta.ToolVersion, meta.Filters.MinConfidence)
+	}
+	if abs, _ := filepath.Abs(dir); meta.Repository.Path != abs {
+		t.Errorf("repository path = %q, want %q", meta.Repository.Path, abs)
+	}
+	if len(meta.Range.Include) != 1 || meta.Range.Include[0] != report.Commits[0].Hash {
+		t.Errorf("range include = %v, want the head %s", meta.Range.Include, report.Commits[0].Hash)
+	}
+	if len(meta.Detectors.Names) == 0 || meta.Detectors.SignatureVersion != detection.SignatureVersion {
+		t.Errorf("detectors = %+v", meta.Detectors)
+	}
+	if meta.FinishedAt.Before(meta.StartedAt) {
+		t.Errorf("finished_at %v is before started_at %v", meta.FinishedAt, meta.StartedAt)
+	}
+}
+
+func TestRunScanSARIF(t *testing.T) {
+	dir := initTestRepo(t)
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"scan", "--format=sarif", dir}, &stdout, &stderr)
+
+	if code != ExitAI {
+		t.Errorf("exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+
+	var log struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Results []struct {
+				RuleID    string `json:"ruleId"`
+				Locations []struct {
+					LogicalLocations []struct {
+						FullyQualifiedName string `json:"fullyQualifiedName"`
+						Kind               string `json:"kind"`
+					} `json:"logicalLocations"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &log); err != nil {
+		t.Fatalf("unmarshal: %v (output: %s)", err, stdout.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || len(log.Runs[0].Results) == 0 {
+		t.Fatalf("expected one run with results, got:\n%s", stdout.String())
+	}
+	for _, r := range log.Runs[0].Results {
+		loc := r.Locations[0].LogicalLocations[0]
+		if loc.Kind != "commit" || len(loc.FullyQualifiedName) != 40 {
+			t.Errorf("%s result located at %+v, want a commit hash", r.RuleID, loc)
+		}
+	}
+}
+
+func TestRunScanMarkdown(t *testing.T) {
+	dir := initTestRepo(t)
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"scan", "--format=markdown", dir}, &stdout, &stderr)
+
+	if code != ExitAI {
+		t.Errorf("exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+	out := stdout.String()
+	for _, want := range []string{"Scanned 3 commit(s), 2 with AI signals.", "| Claude Code |", " fix: update handler\n", " aider: refactor auth module\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestRunScanCSV(t *testing.T) {
+	dir := initTestRepo(t)
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"scan", "--format=csv", dir}, &stdout, &stderr)
+	if code != ExitAI {
+		t.Errorf("exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+	records, err := csv.NewReader(&stdout).ReadAll()
+	if err != nil {
+		t.Fatalf("read CSV: %v", err)
+	}
+	if len(records) < 3 || records[0][0] != "commit" {
+		t.Fatalf("expected a header and a row per finding, got %q", records)
+	}
+	for _, r := range records[1:] {
+		if r[3] != "human@example.com" || r[4] == "" {
+			t.Errorf("row without author email and date: %q", r)
+		}
+	}
+
+	stdout.Reset()
+	code = Run([]string{"scan", "--format=tsv", "--rows=commits", dir}, &stdout, &stderr)
+	if code != ExitAI {
+		t.Errorf("exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+	lines := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "commit\tsubject\t") {
+		t.Errorf("expected a header and 3 commit rows, got:\n%s", stdout.String())
+	}
+}
+
+func TestRunScanRowsErrors(t *testing.T) {
+	dir := initTestRepo(t)
+	for _, args := range [][]string{
+		{"scan", "--format=csv", "--rows=files", dir},
+		{"scan", "--format=json", "--rows=commits", dir},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := Run(args, &stdout, &stderr); code != ExitError {
+			t.Errorf("%v: exit code = %d, want %d", args, code, ExitError)
+		}
+	}
+}
+
+func TestRunScanTemplate(t *testing.T) {
+	dir := initTestRepo(t)
+	tmpl := filepath.Join(t.TempDir(), "slack.tmpl")
+	content := `{{range toolsByCount .Summary.ToolCounts}}{{.Tool}}={{.Count}};{{end}} v{{.Metadata.ToolVersion}}`
+	if err := os.WriteFile(tmpl, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"scan", "--format=template", "--template=" + tmpl, dir}, &stdout, &stderr)
+	if code != ExitAI {
+		t.Errorf("exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+	if want := "Aider=2;Claude=1;Claude Code=1; v" + Version; stdout.String() != want {
+		t.Errorf("got %q, want %q", stdout.String(), want)
+	}
+}
+
+func TestRunScanTemplateErrors(t *testing.T) {
+	dir := initTestRepo(t)
+	tmpl := filepath.Join(t.TempDir(), "bad.tmpl")
+	if err := os.WriteFile(tmpl, []byte("{{.NoSuchField}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"scan", "--format=template", dir},
+		{"scan", "--format=json", "--template=" + tmpl, dir},
+		{"scan", "--format=template", "--template=" + filepath.Join(t.TempDir(), "missing.tmpl"), dir},
+		{"scan", "--format=template", "--template=" + tmpl, dir},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := Run(args, &stdout, &stderr); code != ExitError {
+			t.Errorf("%v: exit code = %d, want %d", args, code, ExitError)
+		}
+	}
+}
+
+func TestRunTextSARIF(t *testing.T) {
+	input := filepath.Join(t.TempDir(), "body.txt")
+	if err := os.WriteFile(input, []byte("I used Claude Code to write this"), 0644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"text", "--format=sarif", "--input=" + input}, &stdout, &stderr)
+	if code != ExitAI {
+		t.Errorf("exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), `"version": "2.1.0"`) || !strings.Contains(stdout.String(), `"ruleId": "toolmention"`) {
+		t.Errorf("expected a SARIF log with a toolmention result, got:\n%s", stdout.String())
+	}
+}
+
+func TestRunScanNDJSON(t *testing.T) {
+	dir := initTestRepo(t)
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"scan", "--format=ndjson", dir}, &stdout, &stderr)
+	if code != ExitAI {
+		t.Errorf("exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+
+	lines := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want 3 commits, metadata and a summary:\n%s", len(lines), stdout.String())
+	}
+	var last output.NDJSONRecord
+	if err := json.Unmarshal([]byte(lines[4]), &last); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if last.Type != output.RecordSummary || last.Summary.TotalCommits != 3 || last.Summary.AICommits != 2 {
+		t.Errorf("last record = %+v, want the summary", last)
+	}
 }
 
 func TestRunScanMinConfidence(t *testing.T) {
@@ -197,33 +407,721 @@ func TestRunScanInvalidRepo(t *testing.T) {
 	}
 }
 
-func TestFilterReport(t *testing.T) {
-	report := scan.Report{
-		Commits: []scan.CommitResult{
-			{
-				Hash: "abc123",
-				Findings: []detection.Finding{
-					{Detector: "toolmention", Tool: "Claude", Confidence: 1, Detail: "text"},
-					{Detector: "coauthor", Tool: "Claude Code", Confidence: 3, Detail: "trailer"},
-				},
-			},
+func TestFilterCommit(t *testing.T) {
+	cr := scan.CommitResult{
+		Hash: "abc123",
+		Findings: []detection.Finding{
+			{Detector: "toolmention", Tool: "Claude", Confidence: 1, Detail: "text"},
+			{Detector: "coauthor", Tool: "Claude Code", Confidence: 3, Detail: "trailer"},
 		},
-		Summary: scan.Summary{
-			TotalCommits: 1,
-			AICommits:    1,
-			ToolCounts:   map[string]int{"Claude": 1, "Claude Code": 1},
-			ByConfidence: map[string]int{"low": 1, "high": 1},
+		Recorded: []detection.Finding{
+			{Detector: "message", Tool: "Aider", Confidence: 2, Detail: "pattern"},
 		},
 	}
 
-	filtered := filterReport(report, 3) // high only
-	if len(filtered.Commits[0].Findings) != 1 {
-		t.Fatalf("expected 1 finding after filter, got %d", len(filtered.Commits[0].Findings))
+	filtered := filterCommit(cr, 3) // high only
+	if len(filtered.Findings) != 1 {
+		t.Fatalf("expected 1 finding after filter, got %d", len(filtered.Findings))
+	}
+	if filtered.Findings[0].Tool != "Claude Code" {
+		t.Errorf("expected Claude Code, got %s", filtered.Findings[0].Tool)
+	}
+	if len(filtered.Recorded) != 0 {
+		t.Errorf("expected recorded findings below high to be dropped, got %+v", filtered.Recorded)
+	}
+	if len(cr.Findings) != 2 {
+		t.Error("filterCommit changed its argument")
+	}
+	if got := filterCommit(cr, 1); len(got.Findings) != 2 || len(got.Recorded) != 1 {
+		t.Errorf("low keeps everything, got %+v", got)
 	}
-	if filtered.Commits[0].Findings[0].Tool != "Claude Code" {
-		t.Errorf("expected Claude Code, got %s", filtered.Commits[0].Findings[0].Tool)
+}
+
+func writeRulesFile(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "rules.yml")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("write rules: %v", err)
+	}
+	return path
+}
+
+func TestRunScanRules(t *testing.T) {
+	dir := initTestRepo(t)
+	rulesPath := writeRulesFile(t, "rules:\n  - tool: House AI\n    confidence: medium\n    message_regex: '^initial commit'\n")
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"scan", "--format=json", "--rules=" + rulesPath, dir}, &stdout, &stderr)
+	if code != ExitAI {
+		t.Fatalf("exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+
+	var report scan.Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if report.Summary.ToolCounts["House AI"] != 1 {
+		t.Errorf("House AI count = %d, want 1", report.Summary.ToolCounts["House AI"])
+	}
+}
+
+func TestRunTextRules(t *testing.T) {
+	rulesPath := writeRulesFile(t, "rules:\n  - tool: House AI\n    confidence: low\n    text_regex: '(?i)house ai'\n")
+	input := filepath.Join(t.TempDir(), "body.txt")
+	if err := os.WriteFile(input, []byte("Drafted with house AI"), 0644); err != nil {
+		t.Fatalf("write input: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"text", "--rules=" + rulesPath, "--input=" + input}, &stdout, &stderr)
+	if code != ExitAI {
+		t.Errorf("exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "House AI") {
+		t.Errorf("expected House AI in output, got:\n%s", stdout.String())
+	}
+}
+
+func TestRunScanRulesInvalid(t *testing.T) {
+	dir := initTestRepo(t)
+	rulesPath := writeRulesFile(t, "rules:\n  - tool: House AI\n")
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"scan", "--rules=" + rulesPath, dir}, &stdout, &stderr)
+	if code != ExitError {
+		t.Errorf("exit code = %d, want %d", code, ExitError)
+	}
+}
+
+func TestRunProfile(t *testing.T) {
+	dir := initTestRepo(t)
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "CLAUDE.md"), []byte("Use gofmt.\n"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := wt.Add("CLAUDE.md"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := wt.Commit("add agent instructions", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "human@example.com", When: time.Now().Add(time.Hour)},
+	}); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"profile", "--format", "json", dir}, &stdout, &stderr)
+	if code != ExitAI {
+		t.Errorf("exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+
+	var report profile.Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout.String())
+	}
+	if len(report.Tools) != 1 || report.Tools[0].ToolID != "claude-code" {
+		t.Errorf("tools = %+v, want claude-code", report.Tools)
+	}
+}
+
+func TestRunProfileNoAI(t *testing.T) {
+	dir := initTestRepo(t)
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"profile", dir}, &stdout, &stderr)
+	if code != ExitNoAI {
+		t.Errorf("exit code = %d, want %d (stderr: %s)", code, ExitNoAI, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "No AI tool configuration found.") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+}
+
+func TestRunScanBase(t *testing.T) {
+	dir := initTestRepo(t)
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	iter, err := repo.Log(&git.LogOptions{})
+	if err != nil {
+		t.Fatalf("log: %v", err)
+	}
+	var root plumbing.Hash
+	_ = iter.ForEach(func(c *object.Commit) error {
+		root = c.Hash
+		return nil
+	})
+	if err := repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/trunk", root)); err != nil {
+		t.Fatalf("set ref: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"scan", "--base=trunk", "--format=json", dir}, &stdout, &stderr)
+	if code != ExitAI {
+		t.Errorf("exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+
+	var report scan.Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("unmarshal: %v (output: %s)", err, stdout.String())
+	}
+	if report.Summary.TotalCommits != 2 {
+		t.Errorf("total_commits = %d, want 2", report.Summary.TotalCommits)
+	}
+
+	// Without origin/HEAD, auto falls back to master, which is HEAD here.
+	stdout.Reset()
+	if code := Run([]string{"scan", "--base=auto", "--format=json", dir}, &stdout, &stderr); code != ExitNoAI {
+		t.Errorf("--base=auto: exit code = %d, want %d (stderr: %s)", code, ExitNoAI, stderr.String())
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil || report.Summary.TotalCommits != 0 {
+		t.Errorf("--base=auto scanned %d commits (%v), want none", report.Summary.TotalCommits, err)
+	}
+}
+
+func TestRunScanBaseErrors(t *testing.T) {
+	dir := initTestRepo(t)
+
+	for _, args := range [][]string{
+		{"scan", "--base=missing", dir},
+		{"scan", "--base=master", "--range=HEAD~1..HEAD", dir},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := Run(args, &stdout, &stderr); code != ExitError {
+			t.Errorf("%v: exit code = %d, want %d", args, code, ExitError)
+		}
+	}
+}
+
+// reportTimes matches the timestamps in a report's metadata.
+var reportTimes = regexp.MustCompile(`"(started|finished)_at": "[^"]*"`)
+
+// withoutTimes blanks the timestamps in a JSON report, so that reports of
+// separate runs can be compared.
+func withoutTimes(report string) string {
+	return reportTimes.ReplaceAllString(report, `"${1}_at": ""`)
+}
+
+func TestRunScanJobs(t *testing.T) {
+	dir := initTestRepo(t)
+
+	var sequential, parallel, stderr bytes.Buffer
+	if code := Run([]string{"scan", "--jobs=1", "--format=json", dir}, &sequential, &stderr); code != ExitAI {
+		t.Fatalf("--jobs=1: exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+	if code := Run([]string{"scan", "--jobs=4", "--format=json", dir}, &parallel, &stderr); code != ExitAI {
+		t.Fatalf("--jobs=4: exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+	if withoutTimes(sequential.String()) != withoutTimes(parallel.String()) {
+		t.Errorf("parallel output differs from sequential:\n%s\nvs\n%s", parallel.String(), sequential.String())
+	}
+}
+
+func TestRunScanCache(t *testing.T) {
+	dir := initTestRepo(t)
+	cacheDir := t.TempDir()
+
+	var uncached, first, second, stderr bytes.Buffer
+	if code := Run([]string{"scan", "--format=json", dir}, &uncached, &stderr); code != ExitAI {
+		t.Fatalf("exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+	for _, out := range []*bytes.Buffer{&first, &second} {
+		if code := Run([]string{"scan", "--format=json", "--cache-dir=" + cacheDir, dir}, out, &stderr); code != ExitAI {
+			t.Fatalf("--cache-dir: exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+		}
+	}
+	if withoutTimes(first.String()) != withoutTimes(uncached.String()) || withoutTimes(second.String()) != withoutTimes(uncached.String()) {
+		t.Errorf("cached output differs from an uncached scan:\n%s\nvs\n%s", second.String(), uncached.String())
+	}
+
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil || len(entries) != 1 {
+		t.Errorf("cache dir holds %d entries (%v), want one detector set", len(entries), err)
+	}
+}
+
+func TestRunScanNotes(t *testing.T) {
+	dir := initTestRepo(t)
+
+	var first, stderr bytes.Buffer
+	if code := Run([]string{"scan", "--format=json", "--write-notes", dir}, &first, &stderr); code != ExitAI {
+		t.Fatalf("--write-notes: exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+	notes, err := gitops.ReadNotes(dir, scan.DefaultNotesRef)
+	if err != nil {
+		t.Fatalf("ReadNotes: %v", err)
+	}
+	if notes.Len() == 0 {
+		t.Fatal("--write-notes stored no notes")
+	}
+
+	var second bytes.Buffer
+	if code := Run([]string{"scan", "--format=json", "--read-notes", dir}, &second, &stderr); code != ExitAI {
+		t.Fatalf("--read-notes: exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+	if withoutTimes(second.String()) != withoutTimes(first.String()) {
+		t.Errorf("output from notes differs:\n%s\nvs\n%s", second.String(), first.String())
+	}
+
+	var custom bytes.Buffer
+	if code := Run([]string{"scan", "--write-notes=custom", "--min-confidence=high", dir}, &custom, &stderr); code != ExitAI {
+		t.Fatalf("--write-notes=custom: exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	if notes, err := gitops.ReadNotes(dir, "refs/notes/custom"); err != nil || notes.Len() == 0 {
+		t.Errorf("no notes under refs/notes/custom (%v)", err)
+	}
+}
+
+func TestRunScanNotesBatches(t *testing.T) {
+	defer func(n int) { notesBatchSize = n }(notesBatchSize)
+	notesBatchSize = 2
+
+	dir := initTestRepo(t)
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"scan", "--write-notes", dir}, &stdout, &stderr); code != ExitAI {
+		t.Fatalf("exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+	notes, err := gitops.ReadNotes(dir, scan.DefaultNotesRef)
+	if err != nil {
+		t.Fatalf("ReadNotes: %v", err)
+	}
+	if notes.Len() != 3 {
+		t.Errorf("stored %d notes, want 3", notes.Len())
+	}
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	ref, err := repo.Reference(scan.DefaultNotesRef, true)
+	if err != nil {
+		t.Fatalf("notes ref: %v", err)
+	}
+	c, err := repo.CommitObject(ref.Hash())
+	if err != nil {
+		t.Fatalf("notes commit: %v", err)
+	}
+	if c.NumParents() != 1 {
+		t.Errorf("notes commit has %d parents, want two batches", c.NumParents())
+	}
+}
+
+// failingWriter accepts the first write and fails every later one.
+type failingWriter struct{ writes int }
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	if w.writes > 1 {
+		return 0, errors.New("disk full")
+	}
+	return len(p), nil
+}
+
+func TestRunScanNotesOnWriteError(t *testing.T) {
+	dir := initTestRepo(t)
+
+	var stderr bytes.Buffer
+	if code := Run([]string{"scan", "--format=ndjson", "--write-notes", dir}, &failingWriter{}, &stderr); code != ExitError {
+		t.Fatalf("exit code = %d, want %d (stderr: %s)", code, ExitError, stderr.String())
+	}
+	notes, err := gitops.ReadNotes(dir, scan.DefaultNotesRef)
+	if err != nil {
+		t.Fatalf("ReadNotes: %v", err)
+	}
+	// The first commit was written out and the second failed; both were
+	// scanned, so both keep their notes.
+	if notes.Len() != 2 {
+		t.Errorf("stored %d notes, want 2 for the commits scanned before the error", notes.Len())
+	}
+}
+
+func TestRunScanRewritesNotes(t *testing.T) {
+	dir := initTestRepo(t)
+	commits, err := gitops.ListCommits(dir, "")
+	if err != nil {
+		t.Fatalf("ListCommits: %v", err)
+	}
+	initial := commits[len(commits)-1].Hash
+
+	// A note copied from a rewritten commit, whose finding the initial
+	// commit does not show.
+	note := scan.Note{
+		Commit:   commits[0].Hash,
+		Findings: []detection.Finding{{Detector: "coauthor", Tool: "Claude Code", ToolID: "claude-code", Confidence: detection.ConfidenceHigh, Detail: "Co-Authored-By trailer"}},
+	}
+	data, err := note.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := gitops.WriteNotes(dir, scan.DefaultNotesRef, map[string][]byte{initial: data}, "copy\n"); err != nil {
+		t.Fatalf("WriteNotes: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"scan", "--format=json", "--read-notes", "--write-notes", dir},
+		{"scan", "--format=json", "--read-notes", dir},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := Run(args, &stdout, &stderr); code != ExitAI {
+			t.Fatalf("%v: exit code = %d (stderr: %s)", args, code, stderr.String())
+		}
+		var report scan.Report
+		if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+			t.Fatalf("unmarshal: %v", err)
+		}
+		if r := report.Commits[len(report.Commits)-1]; r.Hash != initial || len(r.Recorded) != 1 {
+			t.Errorf("%v: initial commit = %+v, want the copied finding recorded", args, r)
+		}
+	}
+}
+
+func TestRunScanAuthorshipLog(t *testing.T) {
+	dir := initTestRepo(t)
+	commits, err := gitops.ListCommits(dir, "")
+	if err != nil {
+		t.Fatalf("ListCommits: %v", err)
+	}
+	initial := commits[len(commits)-1].Hash
+	log := "file0.txt\n  0a1b2c3d4e5f6a7b 1\n---\n" +
+		`{"schema_version": "authorship/3.0.0", "prompts": {"0a1b2c3d4e5f6a7b": {"agent_id": {"tool": "cursor", "model": "gpt-5"}}}}` + "\n"
+	if err := gitops.WriteNotes(dir, gitops.AuthorshipNotesRef, map[string][]byte{initial: []byte(log)}, "git-ai\n"); err != nil {
+		t.Fatalf("WriteNotes: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"scan", "--format=json", dir}, &stdout, &stderr); code != ExitAI {
+		t.Fatalf("exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+	var report scan.Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("unmarshal: %v (output: %s)", err, stdout.String())
+	}
+	r := report.Commits[len(report.Commits)-1]
+	if r.Hash != initial || len(r.Findings) != 1 {
+		t.Fatalf("initial commit = %+v, want one finding from its authorship log", r)
+	}
+	f := r.Findings[0]
+	if f.Detector != "gitai" || f.ToolID != "cursor" || f.Model != "gpt-5" || len(f.Lines) != 1 || f.Lines[0].Path != "file0.txt" {
+		t.Errorf("finding = %+v", f)
+	}
+	if !strings.Contains(stdout.String(), `"ranges": [`) {
+		t.Errorf("JSON output has no line ranges:\n%s", stdout.String())
+	}
+}
+
+func TestRunScanHeadRef(t *testing.T) {
+	dir := initTestRepo(t)
+	commits, err := gitops.ListCommits(dir, "")
+	if err != nil {
+		t.Fatalf("ListCommits: %v", err)
+	}
+	// Check out the tip detached, with the initial commit as the default
+	// branch, the way a pull request job sees the repository.
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	initial := plumbing.NewHash(commits[len(commits)-1].Hash)
+	for _, ref := range []*plumbing.Reference{
+		plumbing.NewHashReference(plumbing.HEAD, plumbing.NewHash(commits[0].Hash)),
+		plumbing.NewHashReference("refs/remotes/origin/main", initial),
+	} {
+		if err := repo.Storer.SetReference(ref); err != nil {
+			t.Fatalf("set ref: %v", err)
+		}
+	}
+	if err := repo.Storer.RemoveReference("refs/heads/master"); err != nil {
+		t.Fatalf("remove master: %v", err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := Run([]string{"scan", "--format=json", "--head-ref=copilot/fix-login", dir}, &stdout, &stderr); code != ExitAI {
+		t.Fatalf("exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+	var report scan.Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("unmarshal: %v (output: %s)", err, stdout.String())
+	}
+	for _, r := range report.Commits {
+		onBranch := false
+		for _, f := range r.Findings {
+			if f.Detector == "refs" && f.ToolID == "copilot-agent" {
+				onBranch = true
+			}
+		}
+		if want := r.Hash != initial.String(); onBranch != want {
+			t.Errorf("commit %s: refs finding %v, want %v", r.Hash[:7], onBranch, want)
+		}
+	}
+}
+
+func TestRunAttribution(t *testing.T) {
+	dir := initTestRepo(t)
+
+	var stdout, stderr bytes.Buffer
+	code := Run([]string{"attribution", "--format", "json", dir}, &stdout, &stderr)
+	if code != ExitAI {
+		t.Errorf("exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+
+	var report attribution.Report
+	if err := json.Unmarshal(stdout.Bytes(), &report); err != nil {
+		t.Fatalf("invalid JSON output: %v\n%s", err, stdout.String())
+	}
+	// Each file holds its commit message: one human line, three from the
+	// Claude commit (high) and one from the aider commit (medium).
+	if report.Total.Total != 5 || report.Total.AI["low"] != 4 || report.Total.AI["high"] != 3 {
+		t.Errorf("total = %+v, want 5 lines with 4 AI and 3 high", report.Total)
+	}
+
+	stdout.Reset()
+	code = Run([]string{"attribution", "--rev", "HEAD~2", dir}, &stdout, &stderr)
+	if code != ExitNoAI {
+		t.Errorf("at HEAD~2: exit code = %d, want %d (stderr: %s)", code, ExitNoAI, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "1 lines in 1 file(s)") {
+		t.Errorf("unexpected output: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	code = Run([]string{"attribution", "--exclude", "file[12].txt", dir}, &stdout, &stderr)
+	if code != ExitNoAI {
+		t.Errorf("with --exclude: exit code = %d, want %d (stderr: %s)", code, ExitNoAI, stderr.String())
+	}
+}
+
+func TestRunAttributionErrors(t *testing.T) {
+	dir := initTestRepo(t)
+
+	for _, args := range [][]string{
+		{"attribution", "--format", "xml", dir},
+		{"attribution", "--rev", "no-such-branch", dir},
+		{"attribution", t.TempDir()},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := Run(args, &stdout, &stderr); code != ExitError {
+			t.Errorf("%v: exit code = %d, want %d", args, code, ExitError)
+		}
+	}
+}
+
+func TestRunDiff(t *testing.T) {
+	dir := initTestRepo(t)
+	reports := t.TempDir()
+
+	var scanned, stderr bytes.Buffer
+	if code := Run([]string{"scan", "--format=json", dir}, &scanned, &stderr); code != ExitAI {
+		t.Fatalf("scan: exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	current := filepath.Join(reports, "current.json")
+	if err := os.WriteFile(current, scanned.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// A report with high-confidence findings only stands in for one made by
+	// an older version that found less.
+	var high bytes.Buffer
+	if code := Run([]string{"scan", "--format=json", "--min-confidence=high", dir}, &high, &stderr); code != ExitAI {
+		t.Fatalf("scan: exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	older := filepath.Join(reports, "older.json")
+	if err := os.WriteFile(older, high.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout bytes.Buffer
+	if code := Run([]string{"diff", older, current}, &stdout, &stderr); code != ExitNoAI {
+		t.Errorf("diff older current: exit code = %d, want %d (stderr: %s)", code, ExitNoAI, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "aider: refactor auth module (gained)") {
+		t.Errorf("expected the Aider commit to have gained signals, got:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := Run([]string{"diff", "--format=json", current, older}, &stdout, &stderr); code != ExitAI {
+		t.Errorf("diff current older: exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+	var diff scan.ReportDiff
+	if err := json.Unmarshal(stdout.Bytes(), &diff); err != nil {
+		t.Fatalf("unmarshal: %v (output: %s)", err, stdout.String())
+	}
+	if diff.Compared != 3 || diff.Regressions != 2 || diff.Summary.Delta.AICommits != -1 {
+		t.Errorf("diff = %+v", diff)
+	}
+
+	stdout.Reset()
+	if code := Run([]string{"diff", current, current}, &stdout, &stderr); code != ExitNoAI {
+		t.Errorf("diff of a report with itself: exit code = %d, want %d", code, ExitNoAI)
+	}
+}
+
+func TestRunDiffErrors(t *testing.T) {
+	report := filepath.Join(t.TempDir(), "report.json")
+	if err := os.WriteFile(report, []byte(`{"commits": [], "summary": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"diff", report},
+		{"diff", "--format=xml", report, report},
+		{"diff", report, filepath.Join(t.TempDir(), "missing.json")},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := Run(args, &stdout, &stderr); code != ExitError {
+			t.Errorf("%v: exit code = %d, want %d", args, code, ExitError)
+		}
+	}
+}
+
+func TestRunMerge(t *testing.T) {
+	dir := initTestRepo(t)
+	reports := t.TempDir()
+
+	// Two overlapping shards of the history.
+	var paths []string
+	for i, rangeFlag := range []string{"HEAD~2..HEAD", "HEAD~1"} {
+		var stdout, stderr bytes.Buffer
+		if code := Run([]string{"scan", "--format=json", "--range=" + rangeFlag, dir}, &stdout, &stderr); code != ExitAI {
+			t.Fatalf("scan %s: exit code = %d (stderr: %s)", rangeFlag, code, stderr.String())
+		}
+		path := filepath.Join(reports, fmt.Sprintf("shard%d.json", i))
+		if err := os.WriteFile(path, stdout.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if code := Run(append([]string{"merge"}, paths...), &stdout, &stderr); code != ExitAI {
+		t.Fatalf("merge: exit code = %d, want %d (stderr: %s)", code, ExitAI, stderr.String())
+	}
+	var merged scan.Report
+	if err := json.Unmarshal(stdout.Bytes(), &merged); err != nil {
+		t.Fatalf("unmarshal: %v (output: %s)", err, stdout.String())
+	}
+	if merged.SchemaVersion != scan.ReportSchemaVersion || len(merged.Sources) != 2 {
+		t.Errorf("schema_version = %d, %d sources; want %d and 2", merged.SchemaVersion, len(merged.Sources), scan.ReportSchemaVersion)
+	}
+	if merged.Summary.TotalCommits != 3 || merged.Summary.AICommits != 2 {
+		t.Errorf("summary = %+v, want 3 commits, 2 with AI signals", merged.Summary)
+	}
+
+	stdout.Reset()
+	if code := Run([]string{"merge", "--format=text", paths[0], paths[0]}, &stdout, &stderr); code != ExitAI {
+		t.Errorf("merge --format=text: exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "Scanned 2 commits, 2 with AI signals") {
+		t.Errorf("expected duplicates merged, got:\n%s", stdout.String())
+	}
+
+	// A single report renders as the scan itself would have.
+	var direct bytes.Buffer
+	if code := Run([]string{"scan", "--format=markdown", "--range=HEAD~2..HEAD", dir}, &direct, &stderr); code != ExitAI {
+		t.Fatalf("scan --format=markdown: exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	stdout.Reset()
+	if code := Run([]string{"merge", "--format=markdown", paths[0]}, &stdout, &stderr); code != ExitAI {
+		t.Errorf("merge --format=markdown: exit code = %d (stderr: %s)", code, stderr.String())
+	}
+	if stdout.String() != direct.String() {
+		t.Errorf("merge --format=markdown:\n%s\nwant:\n%s", stdout.String(), direct.String())
+	}
+}
+
+func TestRunMergeErrors(t *testing.T) {
+	report := filepath.Join(t.TempDir(), "report.json")
+	if err := os.WriteFile(report, []byte(`{"commits": [], "summary": {}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, args := range [][]string{
+		{"merge"},
+		{"merge", "--format=sarif", report},
+		{"merge", report, filepath.Join(t.TempDir(), "missing.json")},
+	} {
+		var stdout, stderr bytes.Buffer
+		if code := Run(args, &stdout, &stderr); code != ExitError {
+			t.Errorf("%v: exit code = %d, want %d", args, code, ExitError)
+		}
+	}
+}
+
+// signatureInputs exercise every built-in detector that results are stored
+// for.
+var signatureInputs = []detection.Input{
+	{CommitEmail: "noreply@anthropic.com", AuthorEmail: "198982749+Copilot@users.noreply.github.com", CommitMessage: "fix: race"},
+	{CommitEmail: "dev@example.com", AuthorEmail: "cursoragent@cursor.com", CommitMessage: "feat: x"},
+	{CommitMessage: "fix: handler\n\nCo-Authored-By: Claude Opus 4 <noreply@anthropic.com>\nCo-authored-by: Alice <alice@example.com>"},
+	{CommitMessage: "aider: refactor auth module"},
+	{CommitMessage: "docs: notes written with ChatGPT and GitHub Copilot, not Claude"},
+	{CommitMessage: "feat: x\n\nAssisted-by: GitHub Copilot:gpt-4o [grep]\nGenerated-by: make-release.sh"},
+	{CommitMessage: "feat: x\n\nAI-Assisted: no"},
+	{CommitMessage: "feat: x\n\nAI-Assisted: yes\nAI-Tool: Cursor\nAI-Model: claude-4-sonnet"},
+	{CommitMessage: "chore: agent setup", Files: []detection.FileChange{
+		{Path: "CLAUDE.md", Action: detection.FileAdded},
+		{Path: ".cursor/rules/go.mdc", Action: detection.FileModified},
+		{Path: "AGENTS.md", Action: detection.FileDeleted},
+		{Path: "main.go", Action: detection.FileAdded},
+	}},
+	{CommitMessage: "initial commit", CommitEmail: "human@example.com", AuthorEmail: "human@example.com"},
+}
+
+// detectorSignatures records, for each detection.SignatureVersion, a digest
+// of what every built-in detector finds in signatureInputs.
+var detectorSignatures = map[string]map[string]string{
+	"2": {
+		"coauthor":    "67b2b6aa0260bf07",
+		"committer":   "70c17fb6743c9f7b",
+		"disclosure":  "455f4ad525c6eaaf",
+		"files":       "868243261b49e518",
+		"message":     "56141f91b79715e5",
+		"toolmention": "1c76f49737aebecb",
+	},
+}
+
+// TestSignatureVersion fails when a built-in detector's findings change
+// without detection.SignatureVersion being bumped, which would leave caches
+// and notes serving results the new code would not produce.
+func TestSignatureVersion(t *testing.T) {
+	detectors, err := allDetectors("", "")
+	if err != nil {
+		t.Fatalf("allDetectors: %v", err)
+	}
+	want, ok := detectorSignatures[detection.SignatureVersion]
+	if !ok {
+		t.Fatalf("no detector signatures recorded for signature version %s", detection.SignatureVersion)
+	}
+
+	got := map[string]string{}
+	for _, d := range detectors {
+		if detection.IsVolatile(d) {
+			continue
+		}
+		h := sha256.New()
+		found := 0
+		for _, input := range signatureInputs {
+			findings := d.Detect(input)
+			found += len(findings)
+			data, err := json.Marshal(findings)
+			if err != nil {
+				t.Fatalf("%s: marshal: %v", d.Name(), err)
+			}
+			h.Write(append(data, '\n'))
+		}
+		if found == 0 {
+			t.Errorf("%s finds nothing in signatureInputs; add an input it detects", d.Name())
+		}
+		got[d.Name()] = hex.EncodeToString(h.Sum(nil))[:16]
 	}
-	if filtered.Summary.AICommits != 1 {
-		t.Errorf("ai_commits = %d, want 1", filtered.Summary.AICommits)
+	if !maps.Equal(got, want) {
+		t.Errorf("built-in detector findings changed under signature version %s.\n"+
+			"Bump detection.SignatureVersion and record these signatures for it:\n%#v\nwas:\n%#v",
+			detection.SignatureVersion, got, want)
 	}
 }
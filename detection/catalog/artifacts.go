@@ -0,0 +1,169 @@
+package catalog
+
+import (
+	"strings"
+
+	"github.com/chaoss/ai-detection-action/internal/glob"
+)
+
+// ArtifactKind classifies the files AI tools keep in a repository.
+type ArtifactKind string
+
+const (
+	ArtifactInstructions ArtifactKind = "instructions" // Agent instruction files such as CLAUDE.md
+	ArtifactConfig       ArtifactKind = "config"       // Tool settings and rule files
+	ArtifactMCP          ArtifactKind = "mcp"          // MCP server configuration
+	ArtifactSession      ArtifactKind = "session"      // Chat history and session logs
+	ArtifactWorkflow     ArtifactKind = "workflow"     // CI workflows that run the tool
+	ArtifactIgnore       ArtifactKind = "gitignore"    // .gitignore entries for the tool's local state
+)
+
+// Artifact is a path pattern identifying a file that belongs to an AI tool.
+// Patterns are matched against slash-separated repository paths; "**"
+// matches any number of directories.
+type Artifact struct {
+	Pattern string       `json:"pattern"`
+	ToolID  string       `json:"tool_id"`
+	Kind    ArtifactKind `json:"kind"`
+}
+
+// artifacts is checked in order; the first matching pattern wins.
+var artifacts = []Artifact{
+	{Pattern: "**/CLAUDE.md", ToolID: "claude-code", Kind: ArtifactInstructions},
+	{Pattern: "**/CLAUDE.local.md", ToolID: "claude-code", Kind: ArtifactInstructions},
+	{Pattern: ".claude/settings.json", ToolID: "claude-code", Kind: ArtifactConfig},
+	{Pattern: ".claude/settings.local.json", ToolID: "claude-code", Kind: ArtifactConfig},
+	{Pattern: ".claude/commands/**", ToolID: "claude-code", Kind: ArtifactConfig},
+	{Pattern: ".claude/agents/**", ToolID: "claude-code", Kind: ArtifactConfig},
+	{Pattern: ".mcp.json", ToolID: "claude-code", Kind: ArtifactMCP},
+
+	{Pattern: "**/AGENTS.md", ToolID: Unspecified.ID, Kind: ArtifactInstructions},
+
+	{Pattern: ".cursorrules", ToolID: "cursor", Kind: ArtifactConfig},
+	{Pattern: ".cursor/rules/**", ToolID: "cursor", Kind: ArtifactConfig},
+	{Pattern: ".cursor/mcp.json", ToolID: "cursor", Kind: ArtifactMCP},
+	{Pattern: ".cursorignore", ToolID: "cursor", Kind: ArtifactConfig},
+
+	{Pattern: ".github/copilot-instructions.md", ToolID: "copilot", Kind: ArtifactInstructions},
+	{Pattern: ".github/instructions/*.instructions.md", ToolID: "copilot", Kind: ArtifactInstructions},
+	{Pattern: ".github/prompts/*.prompt.md", ToolID: "copilot", Kind: ArtifactInstructions},
+	{Pattern: ".vscode/mcp.json", ToolID: "copilot", Kind: ArtifactMCP},
+	{Pattern: ".github/workflows/copilot-setup-steps.yml", ToolID: "copilot-agent", Kind: ArtifactWorkflow},
+	{Pattern: ".github/workflows/copilot-setup-steps.yaml", ToolID: "copilot-agent", Kind: ArtifactWorkflow},
+
+	{Pattern: "**/.aider.chat.history.md", ToolID: "aider", Kind: ArtifactSession},
+	{Pattern: "**/.aider.input.history", ToolID: "aider", Kind: ArtifactSession},
+	{Pattern: ".aider.conf.yml", ToolID: "aider", Kind: ArtifactConfig},
+
+	{Pattern: ".windsurfrules", ToolID: "windsurf", Kind: ArtifactConfig},
+	{Pattern: ".windsurf/rules/**", ToolID: "windsurf", Kind: ArtifactConfig},
+
+	{Pattern: ".clinerules", ToolID: "cline", Kind: ArtifactConfig},
+	{Pattern: ".clinerules/**", ToolID: "cline", Kind: ArtifactConfig},
+
+	{Pattern: ".continue/**", ToolID: "continue", Kind: ArtifactConfig},
+	{Pattern: ".gemini/styleguide.md", ToolID: "gemini-code-assist", Kind: ArtifactConfig},
+	{Pattern: ".gemini/config.yaml", ToolID: "gemini-code-assist", Kind: ArtifactConfig},
+	{Pattern: ".coderabbit.yaml", ToolID: "coderabbit", Kind: ArtifactConfig},
+	{Pattern: ".coderabbit.yml", ToolID: "coderabbit", Kind: ArtifactConfig},
+	{Pattern: ".amazonq/rules/**", ToolID: "amazon-q", Kind: ArtifactConfig},
+	{Pattern: ".codex/**", ToolID: "codex", Kind: ArtifactConfig},
+}
+
+// Artifacts returns every artifact pattern in the catalog.
+func Artifacts() []Artifact {
+	out := make([]Artifact, len(artifacts))
+	copy(out, artifacts)
+	return out
+}
+
+// MatchArtifact returns the artifact pattern that path matches, if any.
+func MatchArtifact(path string) (Artifact, bool) {
+	for _, a := range artifacts {
+		if glob.Match(a.Pattern, path) {
+			return a, true
+		}
+	}
+	return Artifact{}, false
+}
+
+// ToolFor returns the tool an artifact table entry refers to, including
+// Unspecified for artifacts shared by many tools such as AGENTS.md.
+func ToolFor(toolID string) Tool {
+	if toolID == Unspecified.ID {
+		return Unspecified
+	}
+	return MustLookup(toolID)
+}
+
+// WorkflowAction is a GitHub Action that runs an AI tool. Uses is the
+// action's owner/repo, without a version.
+type WorkflowAction struct {
+	Uses   string `json:"uses"`
+	ToolID string `json:"tool_id"`
+}
+
+var workflowActions = []WorkflowAction{
+	{Uses: "anthropics/claude-code-action", ToolID: "claude-code"},
+	{Uses: "anthropics/claude-code-base-action", ToolID: "claude-code"},
+	{Uses: "openai/codex-action", ToolID: "codex"},
+	{Uses: "coderabbitai/ai-pr-reviewer", ToolID: "coderabbit"},
+}
+
+// WorkflowActions returns every known AI GitHub Action.
+func WorkflowActions() []WorkflowAction {
+	out := make([]WorkflowAction, len(workflowActions))
+	copy(out, workflowActions)
+	return out
+}
+
+// MatchWorkflowAction finds the AI action a workflow step's "uses" value
+// refers to. The version and any sub-action path are ignored, so
+// "anthropics/claude-code-action/setup@v1" matches claude-code-action.
+func MatchWorkflowAction(uses string) (WorkflowAction, bool) {
+	uses, _, _ = strings.Cut(strings.ToLower(strings.TrimSpace(uses)), "@")
+	for _, a := range workflowActions {
+		if uses == a.Uses || strings.HasPrefix(uses, a.Uses+"/") {
+			return a, true
+		}
+	}
+	return WorkflowAction{}, false
+}
+
+// IgnoreEntry is the start of a .gitignore entry that keeps an AI tool's
+// local state out of the repository.
+type IgnoreEntry struct {
+	Prefix string `json:"prefix"`
+	ToolID string `json:"tool_id"`
+}
+
+var ignoreEntries = []IgnoreEntry{
+	{Prefix: ".claude", ToolID: "claude-code"},
+	{Prefix: "claude.local.md", ToolID: "claude-code"},
+	{Prefix: ".aider", ToolID: "aider"},
+	{Prefix: ".cursor", ToolID: "cursor"},
+	{Prefix: ".windsurf", ToolID: "windsurf"},
+	{Prefix: ".continue", ToolID: "continue"},
+	{Prefix: ".codex", ToolID: "codex"},
+	{Prefix: ".gemini", ToolID: "gemini-code-assist"},
+	{Prefix: ".amazonq", ToolID: "amazon-q"},
+}
+
+// IgnoreEntries returns every known AI tool .gitignore entry.
+func IgnoreEntries() []IgnoreEntry {
+	out := make([]IgnoreEntry, len(ignoreEntries))
+	copy(out, ignoreEntries)
+	return out
+}
+
+// MatchIgnoreEntry finds the tool whose local state a .gitignore pattern
+// covers. The pattern should have leading "/" and "**/" already removed.
+func MatchIgnoreEntry(pattern string) (IgnoreEntry, bool) {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	for _, e := range ignoreEntries {
+		if strings.HasPrefix(pattern, e.Prefix) {
+			return e, true
+		}
+	}
+	return IgnoreEntry{}, false
+}
@@ -0,0 +1,368 @@
+package catalog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Category classifies how a tool takes part in development.
+type Category string
+
+const (
+	CategoryAgent         Category = "autonomous-agent"         // Works on tasks end to end, often opening its own PRs
+	CategoryIDEAssistant  Category = "ide-assistant"            // Completions and chat inside an editor
+	CategoryChatAssistant Category = "chat-assistant"           // General-purpose chat assistant
+	CategoryReviewBot     Category = "review-bot"               // Automated pull request review
+	CategoryCommitMessage Category = "commit-message-generator" // Writes commit messages from diffs
+)
+
+// Tool describes one AI product. ID is the stable key that findings carry;
+// Name is the display name used in reports.
+type Tool struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Vendor   string   `json:"vendor"`
+	Category Category `json:"category"`
+	// Aliases are other spellings that resolve to this tool.
+	Aliases []string `json:"aliases,omitempty"`
+	// Mentions are the names matched as whole words in free text. Tools whose
+	// names are too ambiguous to match in prose leave this empty.
+	Mentions []string `json:"mentions,omitempty"`
+	// BotEmails are identities the tool commits or co-authors as.
+	BotEmails []string `json:"bot_emails,omitempty"`
+	// GitHubIDs are the numeric user IDs of the tool's GitHub bot accounts.
+	// They survive bot renames, unlike the username in a noreply email (#4).
+	GitHubIDs []int64 `json:"github_ids,omitempty"`
+}
+
+// Unspecified stands in for a tool when a signal shows AI was involved but
+// not which tool, e.g. an "AI-Assisted: yes" trailer. It is not part of All.
+var Unspecified = Tool{
+	ID:   "unspecified",
+	Name: "Unspecified AI tool",
+}
+
+// tools is ordered so that more specific mentions come before the generic
+// names they contain (e.g. "Claude Code" before "Claude").
+var tools = []Tool{
+	{
+		ID:       "claude-code",
+		Name:     "Claude Code",
+		Vendor:   "Anthropic",
+		Category: CategoryAgent,
+		Aliases:  []string{"Claude Code Action", "Claude (Anthropic)", "claude[bot]"},
+		Mentions: []string{"Claude Code"},
+		BotEmails: []string{
+			"noreply@anthropic.com",
+			"209825114+claude[bot]@users.noreply.github.com",
+			"215619710+anthropic-claude[bot]@users.noreply.github.com",
+			"208546643+claude-code-action[bot]@users.noreply.github.com",
+		},
+		GitHubIDs: []int64{209825114, 215619710, 208546643},
+	},
+	{
+		ID:       "claude",
+		Name:     "Claude",
+		Vendor:   "Anthropic",
+		Category: CategoryChatAssistant,
+		Mentions: []string{"Claude"},
+	},
+	{
+		ID:        "copilot",
+		Name:      "GitHub Copilot",
+		Vendor:    "GitHub",
+		Category:  CategoryIDEAssistant,
+		Aliases:   []string{"Copilot", "GitHub Copilot (chat)"},
+		Mentions:  []string{"GitHub Copilot", "Copilot"},
+		BotEmails: []string{"167198135+copilot[bot]@users.noreply.github.com"},
+		GitHubIDs: []int64{167198135},
+	},
+	{
+		ID:        "copilot-agent",
+		Name:      "GitHub Copilot coding agent",
+		Vendor:    "GitHub",
+		Category:  CategoryAgent,
+		Aliases:   []string{"GitHub Copilot (agent)", "Copilot coding agent"},
+		BotEmails: []string{"198982749+copilot@users.noreply.github.com"},
+		GitHubIDs: []int64{198982749},
+	},
+	{
+		ID:        "cursor",
+		Name:      "Cursor",
+		Vendor:    "Anysphere",
+		Category:  CategoryIDEAssistant,
+		Mentions:  []string{"Cursor"},
+		BotEmails: []string{"cursoragent@cursor.com", "206951365+cursor[bot]@users.noreply.github.com"},
+		GitHubIDs: []int64{206951365},
+	},
+	{
+		ID:        "aider",
+		Name:      "Aider",
+		Vendor:    "Aider",
+		Category:  CategoryAgent,
+		Mentions:  []string{"Aider"},
+		BotEmails: []string{"noreply@aider.chat"},
+	},
+	{
+		ID:       "codex",
+		Name:     "OpenAI Codex",
+		Vendor:   "OpenAI",
+		Category: CategoryAgent,
+		Aliases:  []string{"Codex", "Codex via ChatGPT"},
+		Mentions: []string{"OpenAI Codex", "Codex"},
+		BotEmails: []string{
+			"215057067+openai-codex[bot]@users.noreply.github.com",
+			"199175422+chatgpt-codex-connector[bot]@users.noreply.github.com",
+		},
+		GitHubIDs: []int64{215057067, 199175422},
+	},
+	{
+		ID:        "gemini-code-assist",
+		Name:      "Gemini Code Assist",
+		Vendor:    "Google",
+		Category:  CategoryIDEAssistant,
+		Mentions:  []string{"Gemini Code Assist"},
+		BotEmails: []string{"176961590+gemini-code-assist[bot]@users.noreply.github.com"},
+		GitHubIDs: []int64{176961590},
+	},
+	{
+		ID:        "amazon-q",
+		Name:      "Amazon Q Developer",
+		Vendor:    "Amazon",
+		Category:  CategoryIDEAssistant,
+		Aliases:   []string{"Amazon Q"},
+		Mentions:  []string{"Amazon Q Developer", "Amazon Q"},
+		BotEmails: []string{"208079219+amazon-q-developer[bot]@users.noreply.github.com"},
+		GitHubIDs: []int64{208079219},
+	},
+	{
+		ID:        "devin",
+		Name:      "Devin",
+		Vendor:    "Cognition",
+		Category:  CategoryAgent,
+		Mentions:  []string{"Devin"},
+		BotEmails: []string{"158243242+devin-ai-integration[bot]@users.noreply.github.com"},
+		GitHubIDs: []int64{158243242},
+	},
+	{
+		ID:        "cline",
+		Name:      "Cline",
+		Vendor:    "Cline",
+		Category:  CategoryAgent,
+		Mentions:  []string{"Cline"},
+		BotEmails: []string{"205137888+cline[bot]@users.noreply.github.com"},
+		GitHubIDs: []int64{205137888},
+	},
+	{
+		ID:        "continue",
+		Name:      "Continue.dev",
+		Vendor:    "Continue",
+		Category:  CategoryIDEAssistant,
+		Aliases:   []string{"Continue"},
+		Mentions:  []string{"Continue.dev"},
+		BotEmails: []string{"230936708+continue[bot]@users.noreply.github.com"},
+		GitHubIDs: []int64{230936708},
+	},
+	{
+		ID:        "cody",
+		Name:      "Sourcegraph Cody",
+		Vendor:    "Sourcegraph",
+		Category:  CategoryIDEAssistant,
+		Aliases:   []string{"Cody"},
+		Mentions:  []string{"Sourcegraph Cody", "Cody"},
+		BotEmails: []string{"201248094+sourcegraph-cody[bot]@users.noreply.github.com"},
+		GitHubIDs: []int64{201248094},
+	},
+	{
+		ID:        "jetbrains-ai",
+		Name:      "JetBrains AI",
+		Vendor:    "JetBrains",
+		Category:  CategoryIDEAssistant,
+		Mentions:  []string{"JetBrains AI"},
+		BotEmails: []string{"220155983+jetbrains-ai[bot]@users.noreply.github.com"},
+		GitHubIDs: []int64{220155983},
+	},
+	{
+		ID:        "coderabbit",
+		Name:      "CodeRabbit",
+		Vendor:    "CodeRabbit",
+		Category:  CategoryReviewBot,
+		Aliases:   []string{"coderabbitai"},
+		Mentions:  []string{"CodeRabbit"},
+		BotEmails: []string{"136622811+coderabbitai[bot]@users.noreply.github.com"},
+		GitHubIDs: []int64{136622811},
+	},
+	{
+		ID:       "chatgpt",
+		Name:     "ChatGPT",
+		Vendor:   "OpenAI",
+		Category: CategoryChatAssistant,
+		Aliases:  []string{"GPT-4"},
+		Mentions: []string{"ChatGPT", "GPT-4"},
+	},
+	{
+		ID:       "windsurf",
+		Name:     "Windsurf",
+		Vendor:   "Windsurf",
+		Category: CategoryIDEAssistant,
+		Aliases:  []string{"Codeium"},
+		Mentions: []string{"Windsurf"},
+	},
+	{
+		ID:       "entireio",
+		Name:     "EntireIO",
+		Vendor:   "Entire",
+		Category: CategoryAgent,
+		Aliases:  []string{"Entire"},
+	},
+	{
+		ID:       "replit",
+		Name:     "Replit",
+		Vendor:   "Replit",
+		Category: CategoryAgent,
+		Aliases:  []string{"Replit Agent", "Replit Assistant"},
+	},
+	{
+		ID:       "opencommit",
+		Name:     "OpenCommit",
+		Vendor:   "OpenCommit",
+		Category: CategoryCommitMessage,
+		Mentions: []string{"OpenCommit"},
+	},
+	{
+		ID:       "aicommits",
+		Name:     "aicommits",
+		Vendor:   "aicommits",
+		Category: CategoryCommitMessage,
+		Mentions: []string{"aicommits"},
+	},
+}
+
+var (
+	byID       = map[string]int{}
+	byName     = map[string]int{}
+	byEmail    = map[string]int{}
+	byGitHubID = map[int64]int{}
+)
+
+func init() {
+	for i, t := range tools {
+		byID[t.ID] = i
+		byName[strings.ToLower(t.ID)] = i
+		byName[strings.ToLower(t.Name)] = i
+		for _, a := range t.Aliases {
+			byName[strings.ToLower(a)] = i
+		}
+		for _, e := range t.BotEmails {
+			byEmail[strings.ToLower(e)] = i
+		}
+		for _, id := range t.GitHubIDs {
+			byGitHubID[id] = i
+		}
+	}
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slug derives an ID for a tool that is not in the catalog from its display
+// name, e.g. "House AI" -> "house-ai".
+func Slug(name string) string {
+	return strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// Fingerprint returns a digest of the whole catalog: tools, artifacts,
+// workflow actions, ignore entries and agent refs. It changes whenever any of them is
+// edited, which lets callers invalidate results computed with an older
+// catalog.
+func Fingerprint() string {
+	data, err := json.Marshal(struct {
+		Tools           []Tool
+		Unspecified     Tool
+		Artifacts       []Artifact
+		WorkflowActions []WorkflowAction
+		IgnoreEntries   []IgnoreEntry
+		AgentRefs       []AgentRef
+	}{tools, Unspecified, artifacts, workflowActions, ignoreEntries, agentRefs})
+	if err != nil {
+		panic("catalog: " + err.Error())
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// All returns every tool in the catalog.
+func All() []Tool {
+	out := make([]Tool, len(tools))
+	copy(out, tools)
+	return out
+}
+
+// Lookup returns the tool with the given canonical ID.
+func Lookup(id string) (Tool, bool) {
+	i, ok := byID[id]
+	if !ok {
+		return Tool{}, false
+	}
+	return tools[i], true
+}
+
+// MustLookup is like Lookup but panics if id is not in the catalog. It is
+// meant for package-level tables in detectors.
+func MustLookup(id string) Tool {
+	t, ok := Lookup(id)
+	if !ok {
+		panic("catalog: unknown tool ID " + strconv.Quote(id))
+	}
+	return t
+}
+
+// Resolve finds a tool by canonical ID, display name or alias,
+// case-insensitively.
+func Resolve(name string) (Tool, bool) {
+	i, ok := byName[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Tool{}, false
+	}
+	return tools[i], true
+}
+
+// ByEmail finds a tool by one of its bot emails. GitHub noreply emails of the
+// form <numeric-id>+<username>@users.noreply.github.com also match on the
+// numeric ID, so renamed bots are still recognized.
+func ByEmail(email string) (Tool, bool) {
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return Tool{}, false
+	}
+
+	if i, ok := byEmail[email]; ok {
+		return tools[i], true
+	}
+
+	local, ok := strings.CutSuffix(email, "@users.noreply.github.com")
+	if !ok {
+		return Tool{}, false
+	}
+	prefix, _, ok := strings.Cut(local, "+")
+	if !ok {
+		return Tool{}, false
+	}
+	id, err := strconv.ParseInt(prefix, 10, 64)
+	if err != nil {
+		return Tool{}, false
+	}
+	return ByGitHubID(id)
+}
+
+// ByGitHubID finds a tool by the numeric ID of one of its GitHub bot accounts.
+func ByGitHubID(id int64) (Tool, bool) {
+	i, ok := byGitHubID[id]
+	if !ok {
+		return Tool{}, false
+	}
+	return tools[i], true
+}
@@ -0,0 +1,224 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCatalogConsistency(t *testing.T) {
+	ids := map[string]bool{}
+	names := map[string]string{}
+	for _, tool := range All() {
+		if tool.ID == "" || tool.Name == "" || tool.Vendor == "" || tool.Category == "" {
+			t.Errorf("tool %+v is missing a required field", tool)
+		}
+		if ids[tool.ID] {
+			t.Errorf("duplicate tool ID %q", tool.ID)
+		}
+		ids[tool.ID] = true
+
+		for _, n := range append([]string{tool.ID, tool.Name}, tool.Aliases...) {
+			key := strings.ToLower(n)
+			if other, ok := names[key]; ok && other != tool.ID {
+				t.Errorf("name %q is used by both %q and %q", n, other, tool.ID)
+			}
+			names[key] = tool.ID
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	cases := map[string]string{
+		"Claude Code":            "claude-code",
+		"claude (anthropic)":     "claude-code",
+		"CLAUDE-CODE":            "claude-code",
+		"Claude":                 "claude",
+		"Copilot":                "copilot",
+		"GitHub Copilot (agent)": "copilot-agent",
+		"Codex via ChatGPT":      "codex",
+		" Amazon Q ":             "amazon-q",
+	}
+	for name, wantID := range cases {
+		tool, ok := Resolve(name)
+		if !ok {
+			t.Errorf("Resolve(%q): not found", name)
+			continue
+		}
+		if tool.ID != wantID {
+			t.Errorf("Resolve(%q) = %q, want %q", name, tool.ID, wantID)
+		}
+	}
+
+	if _, ok := Resolve("Notepad"); ok {
+		t.Error("Resolve(Notepad): expected no match")
+	}
+}
+
+func TestByEmail(t *testing.T) {
+	cases := []struct {
+		email  string
+		wantID string
+	}{
+		{"noreply@anthropic.com", "claude-code"},
+		{"215619710+anthropic-claude[bot]@users.noreply.github.com", "claude-code"},
+		{"  CursorAgent@Cursor.com ", "cursor"},
+		{"198982749+renamed@users.noreply.github.com", "copilot-agent"},
+		{"199175422+chatgpt-codex-connector[bot]@users.noreply.github.com", "codex"},
+		{"12345+someone@users.noreply.github.com", ""},
+		{"abc+someone@users.noreply.github.com", ""},
+		{"human@example.com", ""},
+		{"", ""},
+	}
+	for _, tc := range cases {
+		tool, ok := ByEmail(tc.email)
+		if tc.wantID == "" {
+			if ok {
+				t.Errorf("ByEmail(%q) = %q, want no match", tc.email, tool.ID)
+			}
+			continue
+		}
+		if !ok || tool.ID != tc.wantID {
+			t.Errorf("ByEmail(%q) = %q, %v, want %q", tc.email, tool.ID, ok, tc.wantID)
+		}
+	}
+}
+
+func TestMustLookupPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for unknown ID")
+		}
+	}()
+	MustLookup("no-such-tool")
+}
+
+func TestArtifactsReferenceKnownTools(t *testing.T) {
+	for _, a := range Artifacts() {
+		if a.ToolID != Unspecified.ID {
+			if _, ok := Lookup(a.ToolID); !ok {
+				t.Errorf("artifact %q references unknown tool %q", a.Pattern, a.ToolID)
+			}
+		}
+		if a.Kind == "" {
+			t.Errorf("artifact %q has no kind", a.Pattern)
+		}
+	}
+}
+
+func TestMatchArtifact(t *testing.T) {
+	cases := map[string]string{
+		"CLAUDE.md":                              "claude-code",
+		"docs/CLAUDE.md":                         "claude-code",
+		"AGENTS.md":                              "unspecified",
+		".cursor/rules/backend/api.mdc":          "cursor",
+		".github/instructions/a.instructions.md": "copilot",
+		"sub/.aider.chat.history.md":             "aider",
+	}
+	for path, want := range cases {
+		a, ok := MatchArtifact(path)
+		if !ok {
+			t.Errorf("MatchArtifact(%q): no match, want %q", path, want)
+			continue
+		}
+		if a.ToolID != want {
+			t.Errorf("MatchArtifact(%q) = %q, want %q", path, a.ToolID, want)
+		}
+	}
+
+	for _, path := range []string{"README.md", "claude.go", ".github/workflows/ci.yml", "docs/.cursorrules.bak"} {
+		if a, ok := MatchArtifact(path); ok {
+			t.Errorf("MatchArtifact(%q) = %q, want no match", path, a.ToolID)
+		}
+	}
+}
+
+func TestMatchAgentRef(t *testing.T) {
+	for _, r := range AgentRefs() {
+		if _, ok := Lookup(r.ToolID); !ok {
+			t.Errorf("agent ref %q references unknown tool %q", r.Prefix, r.ToolID)
+		}
+	}
+
+	cases := map[string]string{
+		"copilot/fix-login-bug":    "copilot-agent",
+		"claude/issue-42-20250101": "claude-code",
+		"codex/add-tests":          "codex",
+		"cursor/refactor-a1b2":     "cursor",
+		"devin/1700000000-docs":    "devin",
+		"entire/checkpoints/v1":    "entireio",
+	}
+	for name, want := range cases {
+		r, ok := MatchAgentRef(name)
+		if !ok {
+			t.Errorf("MatchAgentRef(%q): no match, want %q", name, want)
+			continue
+		}
+		if r.ToolID != want {
+			t.Errorf("MatchAgentRef(%q) = %q, want %q", name, r.ToolID, want)
+		}
+	}
+
+	for _, name := range []string{"main", "copilot", "copilot/", "feature/copilot/x", "claude-code/x", "refs/heads/claude/x"} {
+		if r, ok := MatchAgentRef(name); ok {
+			t.Errorf("MatchAgentRef(%q) = %q, want no match", name, r.ToolID)
+		}
+	}
+}
+
+func TestMatchWorkflowAction(t *testing.T) {
+	cases := map[string]string{
+		"anthropics/claude-code-action@v1":       "claude-code",
+		"Anthropics/Claude-Code-Action@beta":     "claude-code",
+		"anthropics/claude-code-action/setup@v1": "claude-code",
+		"openai/codex-action@main":               "codex",
+	}
+	for uses, want := range cases {
+		a, ok := MatchWorkflowAction(uses)
+		if !ok || a.ToolID != want {
+			t.Errorf("MatchWorkflowAction(%q) = %q, %v; want %q", uses, a.ToolID, ok, want)
+		}
+	}
+	for _, uses := range []string{"actions/checkout@v4", "anthropics/claude-code-actions@v1"} {
+		if a, ok := MatchWorkflowAction(uses); ok {
+			t.Errorf("MatchWorkflowAction(%q) = %q, want no match", uses, a.ToolID)
+		}
+	}
+}
+
+func TestMatchIgnoreEntry(t *testing.T) {
+	cases := map[string]string{
+		".claude":                     "claude-code",
+		".claude/settings.local.json": "claude-code",
+		"CLAUDE.local.md":             "claude-code",
+		".aider*":                     "aider",
+		".cursor":                     "cursor",
+	}
+	for pattern, want := range cases {
+		e, ok := MatchIgnoreEntry(pattern)
+		if !ok || e.ToolID != want {
+			t.Errorf("MatchIgnoreEntry(%q) = %q, %v; want %q", pattern, e.ToolID, ok, want)
+		}
+	}
+	for _, pattern := range []string{"node_modules", ".env", "claude.go"} {
+		if e, ok := MatchIgnoreEntry(pattern); ok {
+			t.Errorf("MatchIgnoreEntry(%q) = %q, want no match", pattern, e.ToolID)
+		}
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	fp := Fingerprint()
+	if len(fp) != 64 {
+		t.Fatalf("Fingerprint() = %q, want a hex SHA-256", fp)
+	}
+	if Fingerprint() != fp {
+		t.Error("Fingerprint is not stable")
+	}
+
+	saved := tools[0].Mentions
+	tools[0].Mentions = append([]string{"Another Name"}, saved...)
+	defer func() { tools[0].Mentions = saved }()
+	if Fingerprint() == fp {
+		t.Error("Fingerprint did not change with the catalog")
+	}
+}
@@ -0,0 +1,41 @@
+package catalog
+
+import "strings"
+
+// AgentRef is a prefix of the branches an AI agent creates for its work, or
+// of the refs it keeps its own state on. Prefixes are matched against branch
+// names with the refs/heads/ or refs/remotes/<remote>/ part removed, and
+// against the full name of any other ref.
+type AgentRef struct {
+	Prefix string `json:"prefix"`
+	ToolID string `json:"tool_id"`
+}
+
+// agentRefs is checked in order; the first matching prefix wins.
+var agentRefs = []AgentRef{
+	{Prefix: "copilot/", ToolID: "copilot-agent"},
+	{Prefix: "claude/", ToolID: "claude-code"},
+	{Prefix: "codex/", ToolID: "codex"},
+	{Prefix: "cursor/", ToolID: "cursor"},
+	{Prefix: "devin/", ToolID: "devin"},
+	{Prefix: "entire/", ToolID: "entireio"}, // Session checkpoint branches
+}
+
+// AgentRefs returns every agent ref prefix in the catalog.
+func AgentRefs() []AgentRef {
+	out := make([]AgentRef, len(agentRefs))
+	copy(out, agentRefs)
+	return out
+}
+
+// MatchAgentRef returns the agent ref prefix that name starts with, if any.
+// The name must continue past the prefix, so a branch named "copilot/" or
+// "copilot" does not match.
+func MatchAgentRef(name string) (AgentRef, bool) {
+	for _, r := range agentRefs {
+		if strings.HasPrefix(name, r.Prefix) && len(name) > len(r.Prefix) {
+			return r, true
+		}
+	}
+	return AgentRef{}, false
+}
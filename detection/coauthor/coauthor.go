@@ -6,39 +6,37 @@ import (
 	"strings"
 
 	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/detection/catalog"
 )
 
-var knownCoAuthorEmails = map[string]string{
-	"noreply@anthropic.com":  "Claude Code",
-	"cursoragent@cursor.com": "Cursor",
-	"noreply@aider.chat":     "Aider",
-}
-
-var coAuthorPattern = regexp.MustCompile(`(?im)^co-authored-by:\s*[^<]*<([^>]+)>`)
+// identityEmail extracts the email from a "Name <email>" trailer value.
+var identityEmail = regexp.MustCompile(`<([^>]+)>`)
 
 type Detector struct{}
 
 func (d *Detector) Name() string { return "coauthor" }
 
-func (d *Detector) Detect(input detection.Input) []detection.Finding {
-	if input.CommitMessage == "" {
-		return nil
-	}
+func (d *Detector) ConcurrencySafe() bool { return true }
 
-	matches := coAuthorPattern.FindAllStringSubmatch(input.CommitMessage, -1)
+func (d *Detector) Detect(input detection.Input) []detection.Finding {
 	var findings []detection.Finding
 	seen := map[string]bool{}
 
-	for _, match := range matches {
+	for _, value := range input.MessageTrailers().Values("Co-authored-by") {
+		match := identityEmail.FindStringSubmatch(value)
+		if match == nil {
+			continue
+		}
 		email := strings.ToLower(strings.TrimSpace(match[1]))
-		if name, ok := knownCoAuthorEmails[email]; ok && !seen[name] {
+		if tool, ok := catalog.ByEmail(email); ok && !seen[tool.ID] {
 			findings = append(findings, detection.Finding{
 				Detector:   d.Name(),
-				Tool:       name,
+				Tool:       tool.Name,
+				ToolID:     tool.ID,
 				Confidence: detection.ConfidenceHigh,
 				Detail:     fmt.Sprintf("Co-Authored-By trailer with email %s", email),
 			})
-			seen[name] = true
+			seen[tool.ID] = true
 		}
 	}
 
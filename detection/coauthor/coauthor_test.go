@@ -58,6 +58,21 @@ func TestDetect(t *testing.T) {
 			message:   "fix: thing\n\nCO-AUTHORED-BY: Claude <noreply@anthropic.com>",
 			wantTools: []string{"Claude Code"},
 		},
+		{
+			name:      "GitHub bot co-author resolved through catalog",
+			message:   "Fix race\n\nCo-authored-by: Copilot <198982749+Copilot@users.noreply.github.com>",
+			wantTools: []string{"GitHub Copilot coding agent"},
+		},
+		{
+			name:      "co-author line outside the trailer block",
+			message:   "fix: bug\n\nCo-Authored-By: Claude <noreply@anthropic.com>\n\nThis paragraph comes last.",
+			wantTools: nil,
+		},
+		{
+			name:      "folded co-author trailer",
+			message:   "fix: bug\n\nCo-Authored-By: Claude Opus 4\n <noreply@anthropic.com>",
+			wantTools: []string{"Claude Code"},
+		},
 		{
 			name:      "no trailers",
 			message:   "just a normal commit message",
@@ -4,25 +4,30 @@ import (
 	"testing"
 
 	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/detection/catalog"
 )
 
 func TestDetectAllKnownEmails(t *testing.T) {
 	d := &Detector{}
-	for email, expectedName := range knownAgentCommitters {
-		input := detection.Input{CommitEmail: email}
-		findings := d.Detect(input)
-		if len(findings) != 1 {
-			t.Errorf("Detect(%q): got %d findings, want 1", email, len(findings))
-			continue
-		}
-		if findings[0].Tool != expectedName {
-			t.Errorf("Detect(%q): tool = %q, want %q", email, findings[0].Tool, expectedName)
-		}
-		if findings[0].Confidence != detection.ConfidenceHigh {
-			t.Errorf("Detect(%q): confidence = %d, want %d", email, findings[0].Confidence, detection.ConfidenceHigh)
-		}
-		if findings[0].Detector != "committer" {
-			t.Errorf("Detect(%q): detector = %q, want %q", email, findings[0].Detector, "committer")
+	for _, tool := range catalog.All() {
+		for _, email := range tool.BotEmails {
+			findings := d.Detect(detection.Input{CommitEmail: email})
+			if len(findings) != 1 {
+				t.Errorf("Detect(%q): got %d findings, want 1", email, len(findings))
+				continue
+			}
+			if findings[0].Tool != tool.Name {
+				t.Errorf("Detect(%q): tool = %q, want %q", email, findings[0].Tool, tool.Name)
+			}
+			if findings[0].ToolID != tool.ID {
+				t.Errorf("Detect(%q): tool ID = %q, want %q", email, findings[0].ToolID, tool.ID)
+			}
+			if findings[0].Confidence != detection.ConfidenceHigh {
+				t.Errorf("Detect(%q): confidence = %d, want %d", email, findings[0].Confidence, detection.ConfidenceHigh)
+			}
+			if findings[0].Detector != "committer" {
+				t.Errorf("Detect(%q): detector = %q, want %q", email, findings[0].Detector, "committer")
+			}
 		}
 	}
 }
@@ -33,8 +38,8 @@ func TestDetectMixedCase(t *testing.T) {
 		input    string
 		wantTool string
 	}{
-		{"198982749+Copilot@users.noreply.github.com", "GitHub Copilot (agent)"},
-		{"209825114+CLAUDE[BOT]@USERS.NOREPLY.GITHUB.COM", "Claude"},
+		{"198982749+Copilot@users.noreply.github.com", "GitHub Copilot coding agent"},
+		{"209825114+CLAUDE[BOT]@USERS.NOREPLY.GITHUB.COM", "Claude Code"},
 		{"136622811+CodeRabbitAI[bot]@users.noreply.github.com", "CodeRabbit"},
 	}
 
@@ -64,8 +69,8 @@ func TestDetectWhitespace(t *testing.T) {
 			t.Errorf("Detect(%q): got %d findings, want 1", email, len(findings))
 			continue
 		}
-		if findings[0].Tool != "Claude" {
-			t.Errorf("Detect(%q): tool = %q, want %q", email, findings[0].Tool, "Claude")
+		if findings[0].Tool != "Claude Code" {
+			t.Errorf("Detect(%q): tool = %q, want %q", email, findings[0].Tool, "Claude Code")
 		}
 	}
 }
@@ -96,9 +101,9 @@ func TestDetectNumericPrefix(t *testing.T) {
 		wantTool string
 	}{
 		// Claude bot with a different username
-		{"209825114+renamed-claude-bot@users.noreply.github.com", "Claude"},
+		{"209825114+renamed-claude-bot@users.noreply.github.com", "Claude Code"},
 		// Copilot with a different username
-		{"198982749+copilot-v2@users.noreply.github.com", "GitHub Copilot (agent)"},
+		{"198982749+copilot-v2@users.noreply.github.com", "GitHub Copilot coding agent"},
 		// CodeRabbit with a different username
 		{"136622811+coderabbit-new@users.noreply.github.com", "CodeRabbit"},
 	}
@@ -130,3 +135,69 @@ func TestDetectNumericPrefixNoFalsePositive(t *testing.T) {
 		}
 	}
 }
+
+func TestDetectAuthorRole(t *testing.T) {
+	d := &Detector{}
+	cases := []struct {
+		name     string
+		input    detection.Input
+		wantTool string
+		wantRole string
+	}{
+		{
+			name: "bot author, web-flow committer",
+			input: detection.Input{
+				AuthorEmail: "198982749+Copilot@users.noreply.github.com",
+				CommitEmail: "noreply@github.com",
+			},
+			wantTool: "GitHub Copilot coding agent",
+			wantRole: detection.RoleAuthor,
+		},
+		{
+			name: "bot committer, human author",
+			input: detection.Input{
+				AuthorEmail: "human@example.com",
+				CommitEmail: "209825114+claude[bot]@users.noreply.github.com",
+			},
+			wantTool: "Claude Code",
+			wantRole: detection.RoleCommitter,
+		},
+		{
+			name: "bot as both author and committer",
+			input: detection.Input{
+				AuthorEmail: "206951365+cursor[bot]@users.noreply.github.com",
+				CommitEmail: "206951365+cursor[bot]@users.noreply.github.com",
+			},
+			wantTool: "Cursor",
+			wantRole: detection.RoleCommitter + "," + detection.RoleAuthor,
+		},
+	}
+
+	for _, tc := range cases {
+		findings := d.Detect(tc.input)
+		if len(findings) != 1 {
+			t.Errorf("%s: got %d findings, want 1", tc.name, len(findings))
+			continue
+		}
+		if findings[0].Tool != tc.wantTool {
+			t.Errorf("%s: tool = %q, want %q", tc.name, findings[0].Tool, tc.wantTool)
+		}
+		if findings[0].Role != tc.wantRole {
+			t.Errorf("%s: role = %q, want %q", tc.name, findings[0].Role, tc.wantRole)
+		}
+	}
+}
+
+func TestDetectDifferentToolsPerRole(t *testing.T) {
+	d := &Detector{}
+	findings := d.Detect(detection.Input{
+		CommitEmail: "209825114+claude[bot]@users.noreply.github.com",
+		AuthorEmail: "206951365+cursor[bot]@users.noreply.github.com",
+	})
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2", len(findings))
+	}
+	if findings[0].Role != detection.RoleCommitter || findings[1].Role != detection.RoleAuthor {
+		t.Errorf("roles = %q, %q, want committer, author", findings[0].Role, findings[1].Role)
+	}
+}
@@ -1,5 +1,12 @@
 package detection
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/chaoss/ai-detection-action/detection/trailers"
+)
+
 // Confidence represents how confident we are that a finding indicates AI involvement.
 type Confidence int
 
@@ -26,26 +33,172 @@ func (c *Confidence) Increment() {
 	*c = min(*c+1, ConfidenceHigh)
 }
 
-// Finding represents a single detection of AI involvement.
+// ParseConfidence parses a confidence name or numeric value.
+func ParseConfidence(s string) (Confidence, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "1", "low":
+		return ConfidenceLow, nil
+	case "2", "medium":
+		return ConfidenceMedium, nil
+	case "3", "high":
+		return ConfidenceHigh, nil
+	default:
+		return 0, fmt.Errorf("invalid confidence %q: use low/1, medium/2, or high/3", s)
+	}
+}
+
+// Roles identify which commit identity a finding matched. A finding that
+// matched both identities lists them comma-separated, e.g. "committer,author".
+const (
+	RoleCommitter = "committer"
+	RoleAuthor    = "author"
+)
+
+// Kind distinguishes evidence of AI involvement from explicit statements that
+// no AI was used.
+type Kind string
+
+const (
+	KindSignal             Kind = ""                    // Evidence of AI involvement
+	KindNegativeDisclosure Kind = "negative-disclosure" // Author states no AI was used, e.g. "AI-Assisted: no"
+)
+
+// Finding represents a single detection of AI involvement, or, when Kind is
+// KindNegativeDisclosure, an explicit statement that there was none.
 type Finding struct {
-	Detector   string     `json:"detector"`
-	Tool       string     `json:"tool"`
-	Confidence Confidence `json:"confidence"`
-	Detail     string     `json:"detail"`
+	Detector      string      `json:"detector"`
+	Tool          string      `json:"tool"`
+	ToolID        string      `json:"tool_id,omitempty"` // Canonical ID from the catalog package
+	Confidence    Confidence  `json:"confidence"`
+	Detail        string      `json:"detail"`
+	Role          string      `json:"role,omitempty"`
+	Model         string      `json:"model,omitempty"`
+	SelfDisclosed bool        `json:"self_disclosed,omitempty"` // The author stated this themselves
+	Kind          Kind        `json:"kind,omitempty"`
+	Lines         []FileLines `json:"lines,omitempty"` // Lines attributed to the tool, when the evidence is line-level
+}
+
+// FileLines lists the lines of one file that a finding attributes to a tool.
+type FileLines struct {
+	Path   string      `json:"path"`
+	Ranges []LineRange `json:"ranges"`
+}
+
+// LineRange is an inclusive range of 1-based line numbers.
+type LineRange struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Count returns the number of lines in the range.
+func (r LineRange) Count() int {
+	return r.End - r.Start + 1
+}
+
+// IndicatesAI reports whether f is evidence of AI involvement rather than a
+// negative disclosure.
+func (f Finding) IndicatesAI() bool {
+	return f.Kind != KindNegativeDisclosure
+}
+
+// AnyIndicatesAI reports whether any of findings is evidence of AI involvement.
+func AnyIndicatesAI(findings []Finding) bool {
+	for _, f := range findings {
+		if f.IndicatesAI() {
+			return true
+		}
+	}
+	return false
 }
 
 // Input provides data for detectors to examine. Each detector reads the fields
 // it cares about and ignores the rest.
 type Input struct {
 	CommitHash    string
-	CommitEmail   string
+	CommitEmail   string // Committer email
+	CommitterName string
+	AuthorEmail   string
+	AuthorName    string
 	CommitMessage string
-	Text          string // For text-only scans (PR body, comments)
+	Trailers      trailers.Trailers // Parsed trailer block of CommitMessage
+	Files         []FileChange      // Files the commit changed relative to its first parent
+	Text          string            // For text-only scans (PR body, comments)
+	AuthorshipLog []byte            // git-ai authorship log attached to the commit as a note, if any
 	RepoPath      string
 }
 
+// File change actions.
+const (
+	FileAdded    = "added"
+	FileModified = "modified"
+	FileDeleted  = "deleted"
+)
+
+// FileChange is a path a commit added, modified or deleted.
+type FileChange struct {
+	Path   string
+	Action string
+}
+
+// MessageTrailers returns the trailer block of CommitMessage, parsing it on
+// demand when the caller did not fill in Trailers. A nil Trailers means not
+// parsed; callers that parsed a message without trailers set an empty one.
+func (in Input) MessageTrailers() trailers.Trailers {
+	if in.Trailers == nil && in.CommitMessage != "" {
+		return trailers.Parse(in.CommitMessage)
+	}
+	return in.Trailers
+}
+
 // Detector is the interface that all detection strategies implement.
 type Detector interface {
 	Name() string
 	Detect(input Input) []Finding
 }
+
+// ConcurrencySafe is an optional interface for detectors whose Detect may be
+// called from several goroutines at once. Parallel scans serialize calls to
+// detectors that do not implement it, or that return false.
+type ConcurrencySafe interface {
+	ConcurrencySafe() bool
+}
+
+// IsConcurrencySafe reports whether d declares itself safe for concurrent use.
+func IsConcurrencySafe(d Detector) bool {
+	cs, ok := d.(ConcurrencySafe)
+	return ok && cs.ConcurrencySafe()
+}
+
+// SignatureVersion identifies the behaviour of the built-in detectors, and is
+// part of the key of cached results and notes. Any change to a built-in
+// detector that alters its findings for an existing commit, even only their
+// order, detail or confidence, must bump it, or caches and notes go on
+// serving the old findings. TestSignatureVersion in package cmd records what
+// each detector finds in a set of sample commits under every version, and
+// fails when those findings change without a bump. Changes to the tool
+// catalog need no bump, as its fingerprint is part of the key too.
+const SignatureVersion = "2"
+
+// Fingerprinted is an optional interface for detectors whose findings depend
+// on configuration, such as a rules file. Fingerprint returns a value that
+// changes whenever that configuration does.
+type Fingerprinted interface {
+	Fingerprint() string
+}
+
+// Volatile is an optional interface for detectors whose findings depend on
+// repository state beyond the commit itself, such as which branches it is
+// on or which notes it has. Their findings are never cached or stored in
+// notes: scans run them on every commit, including one whose other findings
+// come from a cache, and such a detector must work from CommitHash, RepoPath
+// and AuthorshipLog alone. Scans run them after the other detectors, so
+// their findings come last either way.
+type Volatile interface {
+	Volatile() bool
+}
+
+// IsVolatile reports whether d declares its findings volatile.
+func IsVolatile(d Detector) bool {
+	v, ok := d.(Volatile)
+	return ok && v.Volatile()
+}
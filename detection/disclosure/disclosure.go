@@ -0,0 +1,196 @@
+package disclosure
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/detection/catalog"
+)
+
+// Trailer keys that communities use to disclose AI assistance.
+const (
+	keyAssistedBy    = "Assisted-by"
+	keyGeneratedBy   = "Generated-by"
+	keyAIAssisted    = "AI-assisted"
+	keyCoDevelopedBy = "Co-developed-by"
+	keyAITool        = "AI-tool"
+	keyAIModel       = "AI-model"
+)
+
+// identityKeys carry a tool identity in their value. Co-developed-by is
+// usually a human, so it only counts when the identity is a known tool.
+var identityKeys = []struct {
+	key          string
+	requireKnown bool
+}{
+	{keyAssistedBy, false},
+	{keyGeneratedBy, false},
+	{keyCoDevelopedBy, true},
+}
+
+var (
+	affirmative = map[string]bool{"yes": true, "y": true, "true": true, "1": true}
+	negative    = map[string]bool{"no": true, "n": true, "false": true, "0": true, "none": true}
+)
+
+var (
+	emailPattern = regexp.MustCompile(`<([^>]*)>`)
+	modelPattern = regexp.MustCompile(`\(([^)]*)\)`)
+	toolsPattern = regexp.MustCompile(`\[[^\]]*\]`)
+)
+
+// Detector reports AI disclosure trailers such as "Assisted-by: Claude Code",
+// "Generated-by: ChatGPT" and "AI-Assisted: yes". An explicit
+// "AI-Assisted: no" is reported as a negative disclosure. Names the tool
+// catalog does not know are reported at low confidence.
+type Detector struct{}
+
+func (d *Detector) Name() string { return "disclosure" }
+
+func (d *Detector) ConcurrencySafe() bool { return true }
+
+func (d *Detector) Detect(input detection.Input) []detection.Finding {
+	t := input.MessageTrailers()
+	if len(t) == 0 {
+		return nil
+	}
+
+	var findings []detection.Finding
+	seen := map[string]bool{}
+	add := func(f detection.Finding) {
+		if seen[f.ToolID] {
+			return
+		}
+		seen[f.ToolID] = true
+		findings = append(findings, f)
+	}
+
+	for _, ik := range identityKeys {
+		for _, value := range t.Values(ik.key) {
+			id, ok := parseIdentity(value)
+			if !ok || (ik.requireKnown && !id.known) {
+				continue
+			}
+			add(d.finding(id, fmt.Sprintf("%s trailer: %s", ik.key, value), id.confidence()))
+		}
+	}
+
+	for _, value := range t.Values(keyAIAssisted) {
+		word := strings.ToLower(strings.TrimSpace(value))
+		switch {
+		case negative[word]:
+			add(detection.Finding{
+				Detector:      d.Name(),
+				Confidence:    detection.ConfidenceHigh,
+				Detail:        fmt.Sprintf("%s trailer: %s", keyAIAssisted, value),
+				SelfDisclosed: true,
+				Kind:          detection.KindNegativeDisclosure,
+			})
+		case affirmative[word]:
+			// The tool may be named in companion trailers.
+			id := identity{tool: catalog.Unspecified}
+			if name, ok := t.Get(keyAITool); ok {
+				if named, ok := parseIdentity(name); ok {
+					id = named
+				}
+			}
+			if model, ok := t.Get(keyAIModel); ok && id.model == "" {
+				id.model = model
+			}
+			// An explicit yes is high confidence whatever the tool.
+			add(d.finding(id, fmt.Sprintf("%s trailer: %s", keyAIAssisted, value), detection.ConfidenceHigh))
+		default:
+			// Some projects put the tool itself in the value.
+			if id, ok := parseIdentity(value); ok {
+				add(d.finding(id, fmt.Sprintf("%s trailer: %s", keyAIAssisted, value), id.confidence()))
+			}
+		}
+	}
+
+	return findings
+}
+
+func (d *Detector) finding(id identity, detail string, confidence detection.Confidence) detection.Finding {
+	return detection.Finding{
+		Detector:      d.Name(),
+		Tool:          id.tool.Name,
+		ToolID:        id.tool.ID,
+		Confidence:    confidence,
+		Detail:        detail,
+		Model:         id.model,
+		SelfDisclosed: true,
+	}
+}
+
+type identity struct {
+	tool  catalog.Tool
+	model string
+	known bool // tool resolved through the catalog
+}
+
+// confidence is high for a tool the catalog knows. Assisted-by and
+// Generated-by also credit people and scripts, e.g. "Assisted-by: Jane Doe"
+// or "Generated-by: make-release.sh", so any other name is only low.
+func (id identity) confidence() detection.Confidence {
+	if id.known {
+		return detection.ConfidenceHigh
+	}
+	return detection.ConfidenceLow
+}
+
+// parseIdentity works out the tool and model from a disclosure value. It
+// accepts the common forms:
+//
+//	Claude Code <noreply@anthropic.com>
+//	aider (gpt-4o)
+//	GitHub Copilot:gpt-4o [tool1] [tool2]
+//	Claude Opus 4
+func parseIdentity(value string) (identity, bool) {
+	var id identity
+
+	if m := emailPattern.FindStringSubmatch(value); m != nil {
+		if tool, ok := catalog.ByEmail(m[1]); ok {
+			id.tool, id.known = tool, true
+		}
+		value = emailPattern.ReplaceAllString(value, "")
+	}
+	if m := modelPattern.FindStringSubmatch(value); m != nil {
+		id.model = strings.TrimSpace(m[1])
+		value = modelPattern.ReplaceAllString(value, "")
+	}
+	value = toolsPattern.ReplaceAllString(value, "")
+	if name, model, ok := strings.Cut(value, ":"); ok {
+		value = name
+		if id.model == "" {
+			id.model = strings.TrimSpace(model)
+		}
+	}
+
+	name := strings.Join(strings.Fields(value), " ")
+	if id.known {
+		return id, true
+	}
+	if name == "" {
+		return identity{}, false
+	}
+
+	// Try the full name, then shorter leading word runs, so that
+	// "Claude Opus 4" resolves to Claude with "Opus 4" as the model.
+	words := strings.Fields(name)
+	for n := len(words); n > 0; n-- {
+		tool, ok := catalog.Resolve(strings.Join(words[:n], " "))
+		if !ok {
+			continue
+		}
+		id.tool, id.known = tool, true
+		if id.model == "" && n < len(words) {
+			id.model = strings.Join(words[n:], " ")
+		}
+		return id, true
+	}
+
+	id.tool = catalog.Tool{ID: catalog.Slug(name), Name: name}
+	return id, true
+}
@@ -0,0 +1,182 @@
+package disclosure
+
+import (
+	"testing"
+
+	"github.com/chaoss/ai-detection-action/detection"
+)
+
+func TestDetect(t *testing.T) {
+	d := &Detector{}
+	tests := []struct {
+		name      string
+		message   string
+		wantTool  string
+		wantID    string
+		wantModel string
+		wantKind  detection.Kind
+		wantConf  detection.Confidence // high if zero
+	}{
+		{
+			name:     "Assisted-by with catalog tool",
+			message:  "fix: race\n\nAssisted-by: Claude Code",
+			wantTool: "Claude Code",
+			wantID:   "claude-code",
+		},
+		{
+			name:      "Assisted-by in agent:model form",
+			message:   "fix: race\n\nAssisted-by: GitHub Copilot:gpt-4o [grep] [sed]",
+			wantTool:  "GitHub Copilot",
+			wantID:    "copilot",
+			wantModel: "gpt-4o",
+		},
+		{
+			name:      "Generated-by with model in parentheses",
+			message:   "docs: update\n\nGenerated-by: aider (gpt-4o)",
+			wantTool:  "Aider",
+			wantID:    "aider",
+			wantModel: "gpt-4o",
+		},
+		{
+			name:      "model taken from trailing words",
+			message:   "docs: update\n\nAssisted-by: Claude Opus 4",
+			wantTool:  "Claude",
+			wantID:    "claude",
+			wantModel: "Opus 4",
+		},
+		{
+			name:     "Assisted-by with unknown tool keeps its name",
+			message:  "feat: x\n\nAssisted-by: House AI",
+			wantTool: "House AI",
+			wantID:   "house-ai",
+			wantConf: detection.ConfidenceLow,
+		},
+		{
+			name:     "Assisted-by with a person is only low",
+			message:  "feat: x\n\nAssisted-by: Jane Doe",
+			wantTool: "Jane Doe",
+			wantID:   "jane-doe",
+			wantConf: detection.ConfidenceLow,
+		},
+		{
+			name:     "Generated-by with a script is only low",
+			message:  "chore: release\n\nGenerated-by: make-release.sh",
+			wantTool: "make-release.sh",
+			wantID:   "make-release-sh",
+			wantConf: detection.ConfidenceLow,
+		},
+		{
+			name:     "Assisted-by with a sentence is only low",
+			message:  "feat: x\n\nAssisted-by: pair programming with Bob",
+			wantTool: "pair programming with Bob",
+			wantID:   "pair-programming-with-bob",
+			wantConf: detection.ConfidenceLow,
+		},
+		{
+			name:     "AI-Assisted yes with an unknown tool stays high",
+			message:  "feat: x\n\nAI-Assisted: yes\nAI-Tool: House AI",
+			wantTool: "House AI",
+			wantID:   "house-ai",
+		},
+		{
+			name:     "AI-Assisted naming an unknown tool is only low",
+			message:  "feat: x\n\nAI-Assisted: partially",
+			wantTool: "partially",
+			wantID:   "partially",
+			wantConf: detection.ConfidenceLow,
+		},
+		{
+			name:     "Co-developed-by with tool email",
+			message:  "feat: x\n\nCo-developed-by: Claude <noreply@anthropic.com>\nSigned-off-by: Dev <dev@example.com>",
+			wantTool: "Claude Code",
+			wantID:   "claude-code",
+		},
+		{
+			name:    "Co-developed-by with a human is ignored",
+			message: "feat: x\n\nCo-developed-by: Alice <alice@example.com>\nSigned-off-by: Dev <dev@example.com>",
+		},
+		{
+			name:     "AI-Assisted yes without tool",
+			message:  "feat: x\n\nAI-Assisted: yes",
+			wantTool: "Unspecified AI tool",
+			wantID:   "unspecified",
+		},
+		{
+			name:      "AI-Assisted yes with companion trailers",
+			message:   "feat: x\n\nAI-Assisted: true\nAI-Tool: Cursor\nAI-Model: claude-4-sonnet",
+			wantTool:  "Cursor",
+			wantID:    "cursor",
+			wantModel: "claude-4-sonnet",
+		},
+		{
+			name:     "AI-Assisted naming the tool",
+			message:  "feat: x\n\nai-assisted: Windsurf",
+			wantTool: "Windsurf",
+			wantID:   "windsurf",
+		},
+		{
+			name:     "AI-Assisted no is a negative disclosure",
+			message:  "feat: x\n\nAI-Assisted: no",
+			wantKind: detection.KindNegativeDisclosure,
+		},
+		{
+			name:    "disclosure outside the trailer block",
+			message: "feat: x\n\nAssisted-by: Claude Code\n\nMore text after.",
+		},
+		{
+			name:    "no trailers",
+			message: "feat: x",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := d.Detect(detection.Input{CommitMessage: tt.message})
+			if tt.wantTool == "" && tt.wantKind == detection.KindSignal {
+				if len(findings) != 0 {
+					t.Errorf("got %+v, want no findings", findings)
+				}
+				return
+			}
+			if len(findings) != 1 {
+				t.Fatalf("got %d findings, want 1: %+v", len(findings), findings)
+			}
+			f := findings[0]
+			if f.Tool != tt.wantTool || f.ToolID != tt.wantID {
+				t.Errorf("tool = %q (%q), want %q (%q)", f.Tool, f.ToolID, tt.wantTool, tt.wantID)
+			}
+			if f.Model != tt.wantModel {
+				t.Errorf("model = %q, want %q", f.Model, tt.wantModel)
+			}
+			wantConf := tt.wantConf
+			if wantConf == 0 {
+				wantConf = detection.ConfidenceHigh
+			}
+			if f.Confidence != wantConf {
+				t.Errorf("confidence = %v, want %v", f.Confidence, wantConf)
+			}
+			if f.Kind != tt.wantKind {
+				t.Errorf("kind = %q, want %q", f.Kind, tt.wantKind)
+			}
+			if !f.SelfDisclosed {
+				t.Error("expected finding to be marked self-disclosed")
+			}
+			if f.Detector != "disclosure" {
+				t.Errorf("detector = %q, want %q", f.Detector, "disclosure")
+			}
+		})
+	}
+}
+
+func TestDetectMultipleTools(t *testing.T) {
+	d := &Detector{}
+	findings := d.Detect(detection.Input{
+		CommitMessage: "feat: x\n\nAssisted-by: Claude Code\nGenerated-by: ChatGPT\nAssisted-by: claude-code",
+	})
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+	if findings[0].ToolID != "claude-code" || findings[1].ToolID != "chatgpt" {
+		t.Errorf("tools = %q, %q", findings[0].ToolID, findings[1].ToolID)
+	}
+}
@@ -0,0 +1,66 @@
+package files
+
+import (
+	"fmt"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/detection/catalog"
+)
+
+// Detector reports commits that add or modify files belonging to an AI tool,
+// such as CLAUDE.md, .cursorrules or an aider chat history. Patterns come from
+// the catalog's artifact list.
+type Detector struct{}
+
+func (d *Detector) Name() string { return "files" }
+
+func (d *Detector) ConcurrencySafe() bool { return true }
+
+// Detect reports one finding per tool, at the confidence of its strongest
+// file. Adding a session log is strong evidence the tool was used for this
+// commit; adding instructions or configuration shows the tool is being set
+// up; editing an existing file is weaker still. Deletions are ignored.
+func (d *Detector) Detect(input detection.Input) []detection.Finding {
+	var findings []detection.Finding
+	byTool := map[string]int{}
+
+	for _, f := range input.Files {
+		if f.Action == detection.FileDeleted {
+			continue
+		}
+		artifact, ok := catalog.MatchArtifact(f.Path)
+		if !ok {
+			continue
+		}
+
+		confidence := confidenceFor(artifact.Kind, f.Action)
+		detail := fmt.Sprintf("%s %s file %s", f.Action, artifact.Kind, f.Path)
+		if i, ok := byTool[artifact.ToolID]; ok {
+			findings[i].Detail += "; " + detail
+			findings[i].Confidence = max(findings[i].Confidence, confidence)
+			continue
+		}
+
+		tool := catalog.ToolFor(artifact.ToolID)
+		byTool[tool.ID] = len(findings)
+		findings = append(findings, detection.Finding{
+			Detector:   d.Name(),
+			Tool:       tool.Name,
+			ToolID:     tool.ID,
+			Confidence: confidence,
+			Detail:     detail,
+		})
+	}
+
+	return findings
+}
+
+func confidenceFor(kind catalog.ArtifactKind, action string) detection.Confidence {
+	if action != detection.FileAdded {
+		return detection.ConfidenceLow
+	}
+	if kind == catalog.ArtifactSession {
+		return detection.ConfidenceHigh
+	}
+	return detection.ConfidenceMedium
+}
@@ -0,0 +1,111 @@
+package files
+
+import (
+	"testing"
+
+	"github.com/chaoss/ai-detection-action/detection"
+)
+
+func TestDetectAddedArtifacts(t *testing.T) {
+	d := &Detector{}
+	cases := []struct {
+		path           string
+		wantToolID     string
+		wantConfidence detection.Confidence
+	}{
+		{"CLAUDE.md", "claude-code", detection.ConfidenceMedium},
+		{"services/api/CLAUDE.md", "claude-code", detection.ConfidenceMedium},
+		{".claude/settings.json", "claude-code", detection.ConfidenceMedium},
+		{"AGENTS.md", "unspecified", detection.ConfidenceMedium},
+		{".cursorrules", "cursor", detection.ConfidenceMedium},
+		{".cursor/rules/go.mdc", "cursor", detection.ConfidenceMedium},
+		{".github/copilot-instructions.md", "copilot", detection.ConfidenceMedium},
+		{".github/instructions/go.instructions.md", "copilot", detection.ConfidenceMedium},
+		{".aider.chat.history.md", "aider", detection.ConfidenceHigh},
+		{".windsurfrules", "windsurf", detection.ConfidenceMedium},
+		{".clinerules", "cline", detection.ConfidenceMedium},
+		{".clinerules/style.md", "cline", detection.ConfidenceMedium},
+	}
+
+	for _, tc := range cases {
+		findings := d.Detect(detection.Input{
+			Files: []detection.FileChange{{Path: tc.path, Action: detection.FileAdded}},
+		})
+		if len(findings) != 1 {
+			t.Errorf("%s: got %d findings, want 1", tc.path, len(findings))
+			continue
+		}
+		f := findings[0]
+		if f.ToolID != tc.wantToolID {
+			t.Errorf("%s: tool ID = %q, want %q", tc.path, f.ToolID, tc.wantToolID)
+		}
+		if f.Confidence != tc.wantConfidence {
+			t.Errorf("%s: confidence = %v, want %v", tc.path, f.Confidence, tc.wantConfidence)
+		}
+		if f.Detector != "files" {
+			t.Errorf("%s: detector = %q, want %q", tc.path, f.Detector, "files")
+		}
+	}
+}
+
+func TestDetectModifiedIsLow(t *testing.T) {
+	d := &Detector{}
+	findings := d.Detect(detection.Input{
+		Files: []detection.FileChange{{Path: "CLAUDE.md", Action: detection.FileModified}},
+	})
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1", len(findings))
+	}
+	if findings[0].Confidence != detection.ConfidenceLow {
+		t.Errorf("confidence = %v, want low", findings[0].Confidence)
+	}
+	if findings[0].Detail != "modified instructions file CLAUDE.md" {
+		t.Errorf("detail = %q", findings[0].Detail)
+	}
+}
+
+func TestDetectIgnoresDeletions(t *testing.T) {
+	d := &Detector{}
+	findings := d.Detect(detection.Input{
+		Files: []detection.FileChange{{Path: ".cursorrules", Action: detection.FileDeleted}},
+	})
+	if len(findings) != 0 {
+		t.Errorf("got %d findings, want 0: %+v", len(findings), findings)
+	}
+}
+
+func TestDetectOneFindingPerTool(t *testing.T) {
+	d := &Detector{}
+	findings := d.Detect(detection.Input{
+		Files: []detection.FileChange{
+			{Path: "CLAUDE.md", Action: detection.FileModified},
+			{Path: "main.go", Action: detection.FileModified},
+			{Path: ".mcp.json", Action: detection.FileAdded},
+			{Path: ".cursorrules", Action: detection.FileAdded},
+		},
+	})
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+	claude := findings[0]
+	if claude.ToolID != "claude-code" {
+		t.Fatalf("first finding tool ID = %q, want claude-code", claude.ToolID)
+	}
+	if claude.Confidence != detection.ConfidenceMedium {
+		t.Errorf("confidence = %v, want the strongest file's (medium)", claude.Confidence)
+	}
+	want := "modified instructions file CLAUDE.md; added mcp file .mcp.json"
+	if claude.Detail != want {
+		t.Errorf("detail = %q, want %q", claude.Detail, want)
+	}
+	if findings[1].ToolID != "cursor" {
+		t.Errorf("second finding tool ID = %q, want cursor", findings[1].ToolID)
+	}
+}
+
+func TestDetectNoFiles(t *testing.T) {
+	d := &Detector{}
+	if findings := d.Detect(detection.Input{CommitMessage: "add CLAUDE.md"}); len(findings) != 0 {
+		t.Errorf("got %d findings, want 0", len(findings))
+	}
+}
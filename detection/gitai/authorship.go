@@ -0,0 +1,184 @@
+package gitai
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/chaoss/ai-detection-action/detection"
+)
+
+// Log is a parsed git-ai authorship log. The note has two sections separated
+// by a "---" line: an attestation section listing, per file, the line ranges
+// each prompt session wrote,
+//
+//	src/main.go
+//	  a1b2c3d4e5f6a7b8 1-10,15
+//	"docs/with space.md"
+//	  c9d8e7f6a5b4c3d2 3
+//
+// followed by JSON metadata that describes each session, keyed by the same
+// short hash.
+type Log struct {
+	SchemaVersion string
+	BaseCommit    string
+	Files         []File
+	Sessions      map[string]Session
+}
+
+// File holds the attestations for one path, in log order.
+type File struct {
+	Path         string
+	Attestations []Attestation
+}
+
+// Attestation records the lines of a file written during one session.
+type Attestation struct {
+	Session string
+	Lines   []detection.LineRange
+}
+
+// Session describes the agent a prompt session ran in.
+type Session struct {
+	Tool        string // Agent, as git-ai names it, e.g. "cursor" or "claude"
+	ID          string // Agent's own session or conversation ID
+	Model       string
+	HumanAuthor string
+}
+
+// metadata is the JSON section of an authorship log.
+type metadata struct {
+	SchemaVersion string `json:"schema_version"`
+	BaseCommit    string `json:"base_commit_sha"`
+	Prompts       map[string]struct {
+		AgentID struct {
+			Tool  string `json:"tool"`
+			ID    string `json:"id"`
+			Model string `json:"model"`
+		} `json:"agent_id"`
+		HumanAuthor string `json:"human_author"`
+	} `json:"prompts"`
+}
+
+const divider = "---"
+
+// Parse decodes an authorship log. Anything that lacks the divider, has
+// malformed attestation lines or metadata, or declares a schema other than
+// git-ai's authorship schema is an error.
+func Parse(data []byte) (*Log, error) {
+	attestations, meta, ok := cutDivider(data)
+	if !ok {
+		return nil, fmt.Errorf("parsing authorship log: no %q divider", divider)
+	}
+
+	var m metadata
+	if err := json.Unmarshal(meta, &m); err != nil {
+		return nil, fmt.Errorf("parsing authorship log metadata: %w", err)
+	}
+	if !strings.HasPrefix(m.SchemaVersion, "authorship/") {
+		return nil, fmt.Errorf("parsing authorship log: unsupported schema %q", m.SchemaVersion)
+	}
+
+	log := &Log{
+		SchemaVersion: m.SchemaVersion,
+		BaseCommit:    m.BaseCommit,
+		Sessions:      make(map[string]Session, len(m.Prompts)),
+	}
+	for hash, p := range m.Prompts {
+		log.Sessions[hash] = Session{
+			Tool:        p.AgentID.Tool,
+			ID:          p.AgentID.ID,
+			Model:       p.AgentID.Model,
+			HumanAuthor: p.HumanAuthor,
+		}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(attestations))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if line[0] != ' ' && line[0] != '\t' {
+			path, err := unquotePath(line)
+			if err != nil {
+				return nil, fmt.Errorf("parsing authorship log line %d: %w", n, err)
+			}
+			log.Files = append(log.Files, File{Path: path})
+			continue
+		}
+
+		if len(log.Files) == 0 {
+			return nil, fmt.Errorf("parsing authorship log line %d: attestation before any file", n)
+		}
+		session, ranges, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			return nil, fmt.Errorf("parsing authorship log line %d: no line ranges", n)
+		}
+		lines, err := parseRanges(strings.TrimSpace(ranges))
+		if err != nil {
+			return nil, fmt.Errorf("parsing authorship log line %d: %w", n, err)
+		}
+		file := &log.Files[len(log.Files)-1]
+		file.Attestations = append(file.Attestations, Attestation{Session: session, Lines: lines})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("parsing authorship log: %w", err)
+	}
+	return log, nil
+}
+
+// cutDivider splits data around its first divider line.
+func cutDivider(data []byte) (before, after []byte, ok bool) {
+	for start := 0; start <= len(data); {
+		end := bytes.IndexByte(data[start:], '\n')
+		if end < 0 {
+			end = len(data)
+		} else {
+			end += start
+		}
+		if string(bytes.TrimRight(data[start:end], "\r")) == divider {
+			return data[:start], data[min(end+1, len(data)):], true
+		}
+		start = end + 1
+	}
+	return nil, nil, false
+}
+
+// unquotePath returns a file path line, removing the double quotes git-ai
+// puts around paths that contain spaces.
+func unquotePath(line string) (string, error) {
+	if !strings.HasPrefix(line, `"`) {
+		return line, nil
+	}
+	path, err := strconv.Unquote(line)
+	if err != nil {
+		return "", fmt.Errorf("invalid quoted path %s", line)
+	}
+	return path, nil
+}
+
+// parseRanges parses a comma-separated list of line numbers and inclusive
+// ranges, such as "1-10,15".
+func parseRanges(s string) ([]detection.LineRange, error) {
+	var ranges []detection.LineRange
+	for _, part := range strings.Split(s, ",") {
+		first, last, isRange := strings.Cut(part, "-")
+		start, err := strconv.Atoi(first)
+		if err != nil || start < 1 {
+			return nil, fmt.Errorf("invalid line range %q", part)
+		}
+		end := start
+		if isRange {
+			if end, err = strconv.Atoi(last); err != nil || end < start {
+				return nil, fmt.Errorf("invalid line range %q", part)
+			}
+		}
+		ranges = append(ranges, detection.LineRange{Start: start, End: end})
+	}
+	return ranges, nil
+}
@@ -0,0 +1,117 @@
+package gitai
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chaoss/ai-detection-action/detection"
+)
+
+// sampleLog is an authorship log as git-ai writes it: two sessions, one of
+// them across two files, and a quoted path.
+const sampleLog = `src/main.go
+  a1b2c3d4e5f6a7b8 1-10,15
+  c9d8e7f6a5b4c3d2 20-22
+"docs/getting started.md"
+  a1b2c3d4e5f6a7b8 3
+---
+{
+  "schema_version": "authorship/3.0.0",
+  "git_ai_version": "1.0.23",
+  "base_commit_sha": "0123456789abcdef0123456789abcdef01234567",
+  "prompts": {
+    "a1b2c3d4e5f6a7b8": {
+      "agent_id": {"tool": "claude", "id": "5d2c2e1c", "model": "claude-sonnet-4-5"},
+      "human_author": "Dev <dev@example.com>",
+      "messages": [],
+      "total_additions": 12,
+      "total_deletions": 0,
+      "accepted_lines": 11,
+      "overriden_lines": 0
+    },
+    "c9d8e7f6a5b4c3d2": {
+      "agent_id": {"tool": "cursor", "id": "8b1f", "model": "gpt-5"},
+      "human_author": "Dev <dev@example.com>",
+      "messages": [],
+      "total_additions": 3,
+      "total_deletions": 1,
+      "accepted_lines": 3,
+      "overriden_lines": 0
+    }
+  }
+}
+`
+
+func TestParse(t *testing.T) {
+	log, err := Parse([]byte(sampleLog))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if log.SchemaVersion != "authorship/3.0.0" || log.BaseCommit != "0123456789abcdef0123456789abcdef01234567" {
+		t.Errorf("schema %q, base %q", log.SchemaVersion, log.BaseCommit)
+	}
+
+	want := []File{
+		{Path: "src/main.go", Attestations: []Attestation{
+			{Session: "a1b2c3d4e5f6a7b8", Lines: []detection.LineRange{{Start: 1, End: 10}, {Start: 15, End: 15}}},
+			{Session: "c9d8e7f6a5b4c3d2", Lines: []detection.LineRange{{Start: 20, End: 22}}},
+		}},
+		{Path: "docs/getting started.md", Attestations: []Attestation{
+			{Session: "a1b2c3d4e5f6a7b8", Lines: []detection.LineRange{{Start: 3, End: 3}}},
+		}},
+	}
+	if !reflect.DeepEqual(log.Files, want) {
+		t.Errorf("Files = %+v\nwant %+v", log.Files, want)
+	}
+
+	s := log.Sessions["a1b2c3d4e5f6a7b8"]
+	if s.Tool != "claude" || s.ID != "5d2c2e1c" || s.Model != "claude-sonnet-4-5" || s.HumanAuthor != "Dev <dev@example.com>" {
+		t.Errorf("session = %+v", s)
+	}
+	if len(log.Sessions) != 2 {
+		t.Errorf("got %d sessions, want 2", len(log.Sessions))
+	}
+}
+
+func TestParseCRLF(t *testing.T) {
+	log, err := Parse([]byte(strings.ReplaceAll(sampleLog, "\n", "\r\n")))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(log.Files) != 2 || log.Files[0].Path != "src/main.go" {
+		t.Errorf("Files = %+v", log.Files)
+	}
+}
+
+func TestParseEmptyAttestations(t *testing.T) {
+	log, err := Parse([]byte("---\n{\"schema_version\": \"authorship/3.0.0\", \"prompts\": {}}\n"))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(log.Files) != 0 || len(log.Sessions) != 0 {
+		t.Errorf("log = %+v, want empty", log)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	meta := "---\n{\"schema_version\": \"authorship/3.0.0\"}\n"
+	cases := map[string]string{
+		"no divider":          "src/main.go\n  abc 1-2\n",
+		"bad json":            "---\nnot json\n",
+		"other schema":        "---\n{\"schema_version\": \"other/1\"}\n",
+		"no schema":           "---\n{}\n",
+		"orphan attestation":  "  abc 1-2\n" + meta,
+		"no ranges":           "a.go\n  abc\n" + meta,
+		"bad range":           "a.go\n  abc 1-x\n" + meta,
+		"reversed range":      "a.go\n  abc 5-2\n" + meta,
+		"zero line":           "a.go\n  abc 0\n" + meta,
+		"bad quoted path":     "\"a.go\n  abc 1\n" + meta,
+		"scan result as note": `{"commit": "abc", "findings": []}`,
+	}
+	for name, data := range cases {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
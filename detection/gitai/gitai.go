@@ -0,0 +1,118 @@
+// Package gitai reads the authorship logs that git-ai and compatible tools
+// attach to commits as git notes under refs/notes/ai. Each log records which
+// lines of the commit an AI agent wrote, in which session and with which
+// model.
+package gitai
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/detection/catalog"
+)
+
+// agentTools maps git-ai's agent names to catalog IDs where they differ from
+// the catalog's own names and aliases.
+var agentTools = map[string]string{
+	"claude":         "claude-code",
+	"github-copilot": "copilot",
+	"continue-cli":   "continue",
+}
+
+type Detector struct{}
+
+func (d *Detector) Name() string { return "gitai" }
+
+func (d *Detector) ConcurrencySafe() bool { return true }
+
+// Volatile reports true: an authorship log can be fetched after its commit
+// was scanned, so the findings are not cached.
+func (d *Detector) Volatile() bool { return true }
+
+// Detect reports one high-confidence finding per agent and model in the
+// commit's authorship log, carrying the lines attributed to it. Attestations
+// for sessions the metadata does not describe are ignored, as is a note that
+// is not an authorship log.
+func (d *Detector) Detect(input detection.Input) []detection.Finding {
+	if len(input.AuthorshipLog) == 0 {
+		return nil
+	}
+	log, err := Parse(input.AuthorshipLog)
+	if err != nil {
+		return nil
+	}
+
+	type key struct{ toolID, model string }
+	var findings []detection.Finding
+	index := map[key]int{}
+	files := map[key]map[string][]detection.LineRange{}
+
+	for _, file := range log.Files {
+		for _, a := range file.Attestations {
+			session, ok := log.Sessions[a.Session]
+			if !ok || session.Tool == "" {
+				continue
+			}
+			tool := agentTool(session.Tool)
+			k := key{tool.ID, session.Model}
+			if _, ok := index[k]; !ok {
+				index[k] = len(findings)
+				files[k] = map[string][]detection.LineRange{}
+				findings = append(findings, detection.Finding{
+					Detector:   d.Name(),
+					Tool:       tool.Name,
+					ToolID:     tool.ID,
+					Confidence: detection.ConfidenceHigh,
+					Model:      session.Model,
+				})
+			}
+			f := &findings[index[k]]
+			if _, seen := files[k][file.Path]; !seen {
+				f.Lines = append(f.Lines, detection.FileLines{Path: file.Path})
+			}
+			files[k][file.Path] = append(files[k][file.Path], a.Lines...)
+		}
+	}
+
+	for k, i := range index {
+		f := &findings[i]
+		total := 0
+		for j := range f.Lines {
+			f.Lines[j].Ranges = mergeRanges(files[k][f.Lines[j].Path])
+			for _, r := range f.Lines[j].Ranges {
+				total += r.Count()
+			}
+		}
+		f.Detail = fmt.Sprintf("git-ai authorship log attributes %d line(s) in %d file(s)", total, len(f.Lines))
+	}
+	return findings
+}
+
+// agentTool returns the catalog tool for a git-ai agent name, or a tool
+// named after the agent if the catalog does not know it.
+func agentTool(agent string) catalog.Tool {
+	if id, ok := agentTools[agent]; ok {
+		return catalog.MustLookup(id)
+	}
+	if tool, ok := catalog.Resolve(agent); ok {
+		return tool
+	}
+	return catalog.Tool{ID: catalog.Slug(agent), Name: agent}
+}
+
+// mergeRanges sorts ranges and joins those that overlap or touch.
+func mergeRanges(ranges []detection.LineRange) []detection.LineRange {
+	sorted := append([]detection.LineRange(nil), ranges...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	var out []detection.LineRange
+	for _, r := range sorted {
+		if n := len(out); n > 0 && r.Start <= out[n-1].End+1 {
+			out[n-1].End = max(out[n-1].End, r.End)
+			continue
+		}
+		out = append(out, r)
+	}
+	return out
+}
@@ -0,0 +1,77 @@
+package gitai
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/chaoss/ai-detection-action/detection"
+)
+
+func TestDetect(t *testing.T) {
+	d := &Detector{}
+	findings := d.Detect(detection.Input{AuthorshipLog: []byte(sampleLog)})
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+
+	claude := findings[0]
+	if claude.ToolID != "claude-code" || claude.Tool != "Claude Code" || claude.Model != "claude-sonnet-4-5" {
+		t.Errorf("first finding = %+v, want Claude Code with its model", claude)
+	}
+	if claude.Confidence != detection.ConfidenceHigh || claude.Detector != "gitai" {
+		t.Errorf("confidence %v, detector %q", claude.Confidence, claude.Detector)
+	}
+	wantLines := []detection.FileLines{
+		{Path: "src/main.go", Ranges: []detection.LineRange{{Start: 1, End: 10}, {Start: 15, End: 15}}},
+		{Path: "docs/getting started.md", Ranges: []detection.LineRange{{Start: 3, End: 3}}},
+	}
+	if !reflect.DeepEqual(claude.Lines, wantLines) {
+		t.Errorf("lines = %+v\nwant %+v", claude.Lines, wantLines)
+	}
+	if !strings.Contains(claude.Detail, "12 line(s) in 2 file(s)") {
+		t.Errorf("detail = %q", claude.Detail)
+	}
+
+	cursor := findings[1]
+	if cursor.ToolID != "cursor" || cursor.Model != "gpt-5" || len(cursor.Lines) != 1 || cursor.Lines[0].Ranges[0] != (detection.LineRange{Start: 20, End: 22}) {
+		t.Errorf("second finding = %+v, want Cursor on src/main.go:20-22", cursor)
+	}
+}
+
+func TestDetectMergesSessions(t *testing.T) {
+	// Two sessions of the same agent and model become one finding, with
+	// their ranges merged.
+	log := `a.go
+  s1 1-3
+  s2 4,10-12
+  s3 11-20
+  unknown 30
+---
+{"schema_version": "authorship/3.0.0", "prompts": {
+  "s1": {"agent_id": {"tool": "codex", "model": "gpt-5-codex"}},
+  "s2": {"agent_id": {"tool": "codex", "model": "gpt-5-codex"}},
+  "s3": {"agent_id": {"tool": "some-new-agent", "model": ""}}
+}}
+`
+	findings := (&Detector{}).Detect(detection.Input{AuthorshipLog: []byte(log)})
+	if len(findings) != 2 {
+		t.Fatalf("got %d findings, want 2: %+v", len(findings), findings)
+	}
+	want := []detection.LineRange{{Start: 1, End: 4}, {Start: 10, End: 12}}
+	if findings[0].ToolID != "codex" || !reflect.DeepEqual(findings[0].Lines[0].Ranges, want) {
+		t.Errorf("codex finding = %+v, want ranges %v", findings[0], want)
+	}
+	if findings[1].ToolID != "some-new-agent" || findings[1].Tool != "some-new-agent" {
+		t.Errorf("unknown agent finding = %+v", findings[1])
+	}
+}
+
+func TestDetectNoLog(t *testing.T) {
+	d := &Detector{}
+	for _, data := range []string{"", "a note a person wrote\n", `{"commit": "abc"}`} {
+		if findings := d.Detect(detection.Input{AuthorshipLog: []byte(data)}); len(findings) != 0 {
+			t.Errorf("%q: got %d findings, want none", data, len(findings))
+		}
+	}
+}
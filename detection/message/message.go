@@ -6,57 +6,61 @@ import (
 	"strings"
 
 	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/detection/catalog"
+	"github.com/chaoss/ai-detection-action/detection/trailers"
 )
 
+// entireTrailers are the trailer keys EntireIO adds to commits it records.
+var entireTrailers = []string{
+	"Entire-Metadata",
+	"Entire-Metadata-Task",
+	"Entire-Strategy",
+	"Entire-Session",
+	"Entire-Condensation",
+	"Entire-Source-Ref",
+	"Entire-Checkpoint",
+	"Entire-Agent",
+}
+
+var replitSessionID = regexp.MustCompile(`^[a-fA-F0-9-]+$`)
+
 var commitMessagePatterns = []struct {
-	check func(string) (detection.Confidence, bool)
-	name  string
+	check func(msg string, t trailers.Trailers) (detection.Confidence, bool)
+	tool  catalog.Tool
 }{
 	{
-		check: func(msg string) (detection.Confidence, bool) {
+		check: func(msg string, _ trailers.Trailers) (detection.Confidence, bool) {
 			return detection.ConfidenceMedium, strings.HasPrefix(strings.ToLower(msg), "aider:")
 		},
-		name: "Aider",
+		tool: catalog.MustLookup("aider"),
 	},
 	{
-		check: func(msg string) (detection.Confidence, bool) {
+		check: func(msg string, _ trailers.Trailers) (detection.Confidence, bool) {
 			return detection.ConfidenceMedium, strings.Contains(msg, "Generated with Claude Code")
 		},
-		name: "Claude Code",
+		tool: catalog.MustLookup("claude-code"),
 	},
 	{
-		check: func(msg string) (detection.Confidence, bool) {
-			trailers := []string{
-				"Entire-Metadata",
-				"Entire-Metadata-Task",
-				"Entire-Strategy",
-				"Entire-Session",
-				"Entire-Condensation",
-				"Entire-Source-Ref",
-				"Entire-Checkpoint",
-				"Entire-Agent",
-			}
-			for _, trailer := range trailers {
-				if strings.Contains(msg, fmt.Sprintf("\n%s:", trailer)) {
+		check: func(_ string, t trailers.Trailers) (detection.Confidence, bool) {
+			for _, key := range entireTrailers {
+				if t.Has(key) {
 					return detection.ConfidenceMedium, true
 				}
 			}
 			return detection.ConfidenceMedium, false
 		},
-		name: "EntireIO",
+		tool: catalog.MustLookup("entireio"),
 	},
 	{
-		check: func(msg string) (detection.Confidence, bool) {
-			trailerRegex := regexp.MustCompile(`(?m)^Replit-Commit-Author:\s*(Agent|Assistant)(?:\r?\nReplit-Commit-Session-Id:\s*([a-fA-F0-9-]+))?(?:\r?\n|$)`)
-
-			matchResult := trailerRegex.FindStringSubmatch(msg)
-			if len(matchResult) == 0 {
+		check: func(_ string, t trailers.Trailers) (detection.Confidence, bool) {
+			author, ok := t.Get("Replit-Commit-Author")
+			if !ok {
 				// replit not detected
 				return detection.ConfidenceMedium, false
 			}
 
 			var confidence detection.Confidence
-			switch matchResult[1] {
+			switch author {
 			case "Agent":
 				confidence = detection.ConfidenceMedium
 			case "Assistant":
@@ -67,13 +71,13 @@ var commitMessagePatterns = []struct {
 			}
 
 			// if commit session id also present, increase confidence
-			if matchResult[2] != "" {
+			if session, ok := t.Get("Replit-Commit-Session-Id"); ok && replitSessionID.MatchString(session) {
 				confidence.Increment()
 			}
 
 			return confidence, true
 		},
-		name: "Replit",
+		tool: catalog.MustLookup("replit"),
 	},
 }
 
@@ -81,19 +85,23 @@ type Detector struct{}
 
 func (d *Detector) Name() string { return "message" }
 
+func (d *Detector) ConcurrencySafe() bool { return true }
+
 func (d *Detector) Detect(input detection.Input) []detection.Finding {
 	if input.CommitMessage == "" {
 		return nil
 	}
 
+	t := input.MessageTrailers()
 	var findings []detection.Finding
 	for _, p := range commitMessagePatterns {
-		if confidence, isDetected := p.check(input.CommitMessage); isDetected {
+		if confidence, isDetected := p.check(input.CommitMessage, t); isDetected {
 			findings = append(findings, detection.Finding{
 				Detector:   d.Name(),
-				Tool:       p.name,
+				Tool:       p.tool.Name,
+				ToolID:     p.tool.ID,
 				Confidence: confidence,
-				Detail:     fmt.Sprintf("commit message matches %s pattern", p.name),
+				Detail:     fmt.Sprintf("commit message matches %s pattern", p.tool.Name),
 			})
 		}
 	}
@@ -64,25 +64,25 @@ func TestDetect(t *testing.T) {
 		},
 		{
 			name:           "Replit Agent trailer present in a commit",
-			message:        "this is a commit message with\nReplit-Commit-Author: Agent",
+			message:        "this is a commit message with\n\nReplit-Commit-Author: Agent",
 			wantTools:      []string{"Replit"},
 			wantConfidence: []detection.Confidence{detection.ConfidenceMedium},
 		},
 		{
 			name:           "Replit Agent trailer present in a commit with session id",
-			message:        "this is a commit message with\nReplit-Commit-Author: Agent\nReplit-Commit-Session-Id: 1234a1ab-12ab-1234-abcd-0123456a1234",
+			message:        "this is a commit message with\n\nReplit-Commit-Author: Agent\nReplit-Commit-Session-Id: 1234a1ab-12ab-1234-abcd-0123456a1234",
 			wantTools:      []string{"Replit"},
 			wantConfidence: []detection.Confidence{detection.ConfidenceHigh},
 		},
 		{
 			name:           "Replit Assistant trailer present in a commit",
-			message:        "this is a commit message with\nReplit-Commit-Author: Assistant",
+			message:        "this is a commit message with\n\nReplit-Commit-Author: Assistant",
 			wantTools:      []string{"Replit"},
 			wantConfidence: []detection.Confidence{detection.ConfidenceLow},
 		},
 		{
 			name:           "Replit Assistant trailer present in a commit with session id",
-			message:        "this is a commit message with\nReplit-Commit-Author: Assistant\nReplit-Commit-Session-Id: 1234a1ab-12ab-1234-abcd-0123456a1234",
+			message:        "this is a commit message with\n\nReplit-Commit-Author: Assistant\nReplit-Commit-Session-Id: 1234a1ab-12ab-1234-abcd-0123456a1234",
 			wantTools:      []string{"Replit"},
 			wantConfidence: []detection.Confidence{detection.ConfidenceMedium},
 		},
@@ -110,6 +110,24 @@ func TestDetect(t *testing.T) {
 			wantTools:      []string{"Replit"},
 			wantConfidence: []detection.Confidence{detection.ConfidenceLow},
 		},
+		{
+			name:           "Replit trailer in the title paragraph is not a trailer",
+			message:        "this is a commit message with\nReplit-Commit-Author: Agent",
+			wantTools:      nil,
+			wantConfidence: nil,
+		},
+		{
+			name:           "Replit Agent trailer with folded session id",
+			message:        "fix\n\nReplit-Commit-Author: Agent\nReplit-Commit-Session-Id:\n 1234a1ab-12ab-1234-abcd-0123456a1234",
+			wantTools:      []string{"Replit"},
+			wantConfidence: []detection.Confidence{detection.ConfidenceHigh},
+		},
+		{
+			name:           "EntireIO trailer with lowercase key",
+			message:        "fix\n\nentire-checkpoint: ab123cdefg12",
+			wantTools:      []string{"EntireIO"},
+			wantConfidence: []detection.Confidence{detection.ConfidenceMedium},
+		},
 		{
 			name:           "Some other Replit product trailer (not agent or asst) present in a commit",
 			message:        "this is a commit message with\nReplit-Commit-Author: SomeOtherReplitProduct",
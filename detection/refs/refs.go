@@ -0,0 +1,121 @@
+// Package refs detects commits made on branches that AI agents create, such
+// as the copilot/* branches of Copilot's coding agent or claude/* branches
+// of Claude Code, and on refs agents keep their state on.
+package refs
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/detection/catalog"
+	"github.com/chaoss/ai-detection-action/gitops"
+)
+
+// Detector reports the agent refs each commit was made on, as mapped by
+// gitops.RefCommits. The refs of a repository are read once, the first time
+// one of its commits is examined. Which refs exist changes over time, so the
+// detector is detection.Volatile.
+type Detector struct {
+	// HeadRef is the name of the branch HEAD was checked out from, when the
+	// repository has no ref by that name: a pull request CI job checks out a
+	// detached commit, and knows the branch only from the event, e.g.
+	// GITHUB_HEAD_REF. HEAD is treated as that branch.
+	HeadRef string
+
+	mu    sync.Mutex
+	repos map[string]map[string][]string // repo path -> commit -> agent refs
+}
+
+func (d *Detector) Name() string { return "refs" }
+
+func (d *Detector) ConcurrencySafe() bool { return true }
+
+func (d *Detector) Volatile() bool { return true }
+
+// Detect reports one medium-confidence finding per tool whose refs the commit
+// was made on. A repository whose refs cannot be read yields no findings.
+func (d *Detector) Detect(input detection.Input) []detection.Finding {
+	if input.CommitHash == "" || input.RepoPath == "" {
+		return nil
+	}
+
+	var findings []detection.Finding
+	branches := map[string][]string{}
+	for _, name := range d.commitRefs(input.RepoPath)[input.CommitHash] {
+		branch := gitops.BranchName(name)
+		agentRef, ok := catalog.MatchAgentRef(branch)
+		if !ok {
+			continue
+		}
+		if _, ok := branches[agentRef.ToolID]; !ok {
+			tool := catalog.MustLookup(agentRef.ToolID)
+			findings = append(findings, detection.Finding{
+				Detector:   d.Name(),
+				Tool:       tool.Name,
+				ToolID:     tool.ID,
+				Confidence: detection.ConfidenceMedium,
+			})
+		}
+		// A local branch and its remote-tracking branch share a name.
+		if !slices.Contains(branches[agentRef.ToolID], branch) {
+			branches[agentRef.ToolID] = append(branches[agentRef.ToolID], branch)
+		}
+	}
+
+	for i := range findings {
+		names := branches[findings[i].ToolID]
+		if len(names) == 1 {
+			findings[i].Detail = fmt.Sprintf("made on agent branch %s", names[0])
+		} else {
+			findings[i].Detail = fmt.Sprintf("made on agent branches %s", strings.Join(names, ", "))
+		}
+	}
+	return findings
+}
+
+// commitRefs returns the agent refs of each commit in the repository at
+// repoPath, reading them on first use.
+func (d *Detector) commitRefs(repoPath string) map[string][]string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if commits, ok := d.repos[repoPath]; ok {
+		return commits
+	}
+	if d.repos == nil {
+		d.repos = map[string]map[string][]string{}
+	}
+	// Refs that cannot be read are remembered as none, so the error is not
+	// hit again for every commit.
+	commits, _ := d.readRefs(repoPath)
+	d.repos[repoPath] = commits
+	return commits
+}
+
+// readRefs maps the commits of repoPath to the agent refs among its refs and
+// HeadRef.
+func (d *Detector) readRefs(repoPath string) (map[string][]string, error) {
+	all, err := gitops.ListRefs(repoPath)
+	if err != nil {
+		return nil, err
+	}
+	var agent []gitops.Ref
+	for _, ref := range all {
+		if _, ok := catalog.MatchAgentRef(gitops.BranchName(ref.Name)); ok {
+			agent = append(agent, ref)
+		}
+	}
+	if _, ok := catalog.MatchAgentRef(d.HeadRef); ok {
+		head, err := gitops.ResolveRevision(repoPath, "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		agent = append(agent, gitops.Ref{Name: d.HeadRef, Commit: head})
+	}
+	if len(agent) == 0 {
+		return nil, nil
+	}
+	return gitops.RefCommits(repoPath, agent)
+}
@@ -0,0 +1,120 @@
+package refs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// initRepo commits "base" on main, then "agent" on a copilot/fix branch
+// that origin also has, and leaves HEAD detached at a "pr" commit on top of
+// base that no branch points at, as a pull request checkout does.
+func initRepo(t *testing.T) (string, map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+
+	hashes := map[string]string{}
+	commit := func(name string) plumbing.Hash {
+		if err := os.WriteFile(filepath.Join(dir, name+".txt"), []byte(name), 0o644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if _, err := wt.Add(name + ".txt"); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+		sig := &object.Signature{Name: "Test", Email: "human@example.com", When: time.Now().Add(time.Duration(len(hashes)) * time.Second)}
+		h, err := wt.Commit(name, &git.CommitOptions{Author: sig, Committer: sig})
+		if err != nil {
+			t.Fatalf("commit: %v", err)
+		}
+		hashes[name] = h.String()
+		return h
+	}
+
+	base := commit("base")
+	if err := repo.Storer.SetReference(plumbing.NewHashReference("refs/heads/main", base)); err != nil {
+		t.Fatalf("set ref: %v", err)
+	}
+	agent := commit("agent")
+	for _, name := range []plumbing.ReferenceName{"refs/heads/copilot/fix", "refs/remotes/origin/copilot/fix"} {
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(name, agent)); err != nil {
+			t.Fatalf("set ref: %v", err)
+		}
+	}
+
+	if err := wt.Checkout(&git.CheckoutOptions{Hash: base}); err != nil {
+		t.Fatalf("checkout: %v", err)
+	}
+	commit("pr")
+	return dir, hashes
+}
+
+func TestDetect(t *testing.T) {
+	dir, hashes := initRepo(t)
+	d := &Detector{}
+
+	findings := d.Detect(detection.Input{CommitHash: hashes["agent"], RepoPath: dir})
+	if len(findings) != 1 {
+		t.Fatalf("got %d findings, want 1: %+v", len(findings), findings)
+	}
+	f := findings[0]
+	if f.ToolID != "copilot-agent" || f.Confidence != detection.ConfidenceMedium || f.Detector != "refs" {
+		t.Errorf("finding = %+v", f)
+	}
+	// The local and remote-tracking branch are listed once.
+	if f.Detail != "made on agent branch copilot/fix" {
+		t.Errorf("detail = %q", f.Detail)
+	}
+
+	for _, name := range []string{"base", "pr"} {
+		if findings := d.Detect(detection.Input{CommitHash: hashes[name], RepoPath: dir}); len(findings) != 0 {
+			t.Errorf("%s: got %d findings, want none", name, len(findings))
+		}
+	}
+}
+
+func TestDetectHeadRef(t *testing.T) {
+	dir, hashes := initRepo(t)
+
+	d := &Detector{HeadRef: "claude/issue-7"}
+	findings := d.Detect(detection.Input{CommitHash: hashes["pr"], RepoPath: dir})
+	if len(findings) != 1 || findings[0].ToolID != "claude-code" || findings[0].Detail != "made on agent branch claude/issue-7" {
+		t.Errorf("findings = %+v, want claude-code on claude/issue-7", findings)
+	}
+	if findings := d.Detect(detection.Input{CommitHash: hashes["base"], RepoPath: dir}); len(findings) != 0 {
+		t.Errorf("base: got %d findings, want none", len(findings))
+	}
+
+	// A head ref that is not an agent branch adds nothing.
+	d = &Detector{HeadRef: "feature/login"}
+	if findings := d.Detect(detection.Input{CommitHash: hashes["pr"], RepoPath: dir}); len(findings) != 0 {
+		t.Errorf("feature/login: got %d findings, want none", len(findings))
+	}
+}
+
+func TestDetectNoRepo(t *testing.T) {
+	d := &Detector{}
+	for _, input := range []detection.Input{
+		{Text: "copilot/fix"},
+		{CommitHash: "0123456789abcdef0123456789abcdef01234567"},
+		{CommitHash: "0123456789abcdef0123456789abcdef01234567", RepoPath: t.TempDir()},
+	} {
+		if findings := d.Detect(input); len(findings) != 0 {
+			t.Errorf("%+v: got %d findings, want none", input, len(findings))
+		}
+	}
+}
@@ -0,0 +1,333 @@
+package rules
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/detection/catalog"
+	"gopkg.in/yaml.v3"
+)
+
+// File is the on-disk layout of a rules file.
+//
+//	rules:
+//	  - name: acme-bot
+//	    tool: Acme Assistant
+//	    confidence: high
+//	    email: assistant@acme.internal
+//	  - tool: Acme Assistant
+//	    confidence: medium
+//	    trailer:
+//	      key: Acme-Assisted
+//	      value: '^(yes|true)$'
+type File struct {
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+// Rule describes one custom signature. Exactly one matcher field (Email,
+// EmailRegex, Trailer, MessageRegex or TextRegex) must be set.
+type Rule struct {
+	Name       string `json:"name,omitempty" yaml:"name,omitempty"`
+	Tool       string `json:"tool" yaml:"tool"`
+	Confidence string `json:"confidence" yaml:"confidence"`
+
+	Email        string   `json:"email,omitempty" yaml:"email,omitempty"`
+	EmailRegex   string   `json:"email_regex,omitempty" yaml:"email_regex,omitempty"`
+	Trailer      *Trailer `json:"trailer,omitempty" yaml:"trailer,omitempty"`
+	MessageRegex string   `json:"message_regex,omitempty" yaml:"message_regex,omitempty"`
+	TextRegex    string   `json:"text_regex,omitempty" yaml:"text_regex,omitempty"`
+}
+
+// Trailer matches a commit message trailer by key (case-insensitive) and,
+// optionally, a regex applied to its value.
+type Trailer struct {
+	Key   string `json:"key" yaml:"key"`
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+}
+
+// matcher reports whether input matches, with a human-readable detail and,
+// for identity matchers, the role that matched.
+type matcher func(input detection.Input) (detail string, role string, ok bool)
+
+type compiledRule struct {
+	name       string
+	tool       string
+	toolID     string
+	confidence detection.Confidence
+	match      matcher
+}
+
+// Detector runs the rules loaded from a rules file.
+type Detector struct {
+	rules       []compiledRule
+	fingerprint string
+}
+
+func (d *Detector) Name() string { return "rules" }
+
+func (d *Detector) ConcurrencySafe() bool { return true }
+
+// Fingerprint returns a digest of the rules the detector was compiled from.
+func (d *Detector) Fingerprint() string { return d.fingerprint }
+
+func (d *Detector) Detect(input detection.Input) []detection.Finding {
+	var findings []detection.Finding
+	for _, r := range d.rules {
+		detail, role, ok := r.match(input)
+		if !ok {
+			continue
+		}
+		findings = append(findings, detection.Finding{
+			Detector:   d.Name(),
+			Tool:       r.tool,
+			ToolID:     r.toolID,
+			Confidence: r.confidence,
+			Detail:     fmt.Sprintf("rule %q: %s", r.name, detail),
+			Role:       role,
+		})
+	}
+	return findings
+}
+
+// Load reads a rules file and compiles it into a Detector. Files ending in
+// .json are decoded as JSON; anything else is decoded as YAML.
+func Load(path string) (*Detector, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading rules: %w", err)
+	}
+
+	var d *Detector
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		d, err = ParseJSON(data)
+	} else {
+		d, err = ParseYAML(data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return d, nil
+}
+
+// ParseYAML compiles a YAML rules document.
+func ParseYAML(data []byte) (*Detector, error) {
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("parsing rules: %w", err)
+	}
+	return Compile(f)
+}
+
+// ParseJSON compiles a JSON rules document.
+func ParseJSON(data []byte) (*Detector, error) {
+	var f File
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return nil, fmt.Errorf("parsing rules: %w", err)
+	}
+	return Compile(f)
+}
+
+// Compile validates the rules in f and builds a Detector from them. A file
+// without rules is an error, since it would detect nothing.
+func Compile(f File) (*Detector, error) {
+	if len(f.Rules) == 0 {
+		return nil, errors.New("no rules defined")
+	}
+	d := &Detector{}
+	for i, r := range f.Rules {
+		cr, err := compileRule(r)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+		d.rules = append(d.rules, cr)
+	}
+
+	// The same rules give the same fingerprint whether they were written as
+	// YAML or JSON.
+	data, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	d.fingerprint = hex.EncodeToString(sum[:])
+	return d, nil
+}
+
+func compileRule(r Rule) (compiledRule, error) {
+	tool := strings.TrimSpace(r.Tool)
+	if tool == "" {
+		return compiledRule{}, errors.New("tool is required")
+	}
+
+	// Tools the catalog knows are reported under their canonical name so they
+	// count together with built-in findings; others get a derived ID.
+	toolID := catalog.Slug(tool)
+	if t, ok := catalog.Resolve(tool); ok {
+		tool, toolID = t.Name, t.ID
+	}
+
+	confidence, err := detection.ParseConfidence(r.Confidence)
+	if err != nil {
+		return compiledRule{}, err
+	}
+
+	name := strings.TrimSpace(r.Name)
+	if name == "" {
+		name = tool
+	}
+
+	var matchers []matcher
+	if r.Email != "" {
+		matchers = append(matchers, emailExact(r.Email))
+	}
+	if r.EmailRegex != "" {
+		m, err := emailRegex(r.EmailRegex)
+		if err != nil {
+			return compiledRule{}, err
+		}
+		matchers = append(matchers, m)
+	}
+	if r.Trailer != nil {
+		m, err := trailer(*r.Trailer)
+		if err != nil {
+			return compiledRule{}, err
+		}
+		matchers = append(matchers, m)
+	}
+	if r.MessageRegex != "" {
+		m, err := messageRegex(r.MessageRegex)
+		if err != nil {
+			return compiledRule{}, err
+		}
+		matchers = append(matchers, m)
+	}
+	if r.TextRegex != "" {
+		m, err := textRegex(r.TextRegex)
+		if err != nil {
+			return compiledRule{}, err
+		}
+		matchers = append(matchers, m)
+	}
+
+	if len(matchers) != 1 {
+		return compiledRule{}, fmt.Errorf("rule %q must set exactly one of email, email_regex, trailer, message_regex or text_regex", name)
+	}
+
+	return compiledRule{
+		name:       name,
+		tool:       tool,
+		toolID:     toolID,
+		confidence: confidence,
+		match:      matchers[0],
+	}, nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// matchIdentity checks the committer and author emails in turn and reports the
+// roles whose email satisfies match.
+func matchIdentity(input detection.Input, match func(email string) bool) (string, string, bool) {
+	identities := []struct {
+		role  string
+		email string
+	}{
+		{detection.RoleCommitter, normalizeEmail(input.CommitEmail)},
+		{detection.RoleAuthor, normalizeEmail(input.AuthorEmail)},
+	}
+
+	var roles, details []string
+	for _, id := range identities {
+		if id.email == "" || !match(id.email) {
+			continue
+		}
+		roles = append(roles, id.role)
+		details = append(details, fmt.Sprintf("%s email %s", id.role, id.email))
+	}
+	if len(roles) == 0 {
+		return "", "", false
+	}
+	return strings.Join(details, ", "), strings.Join(roles, ","), true
+}
+
+func emailExact(want string) matcher {
+	want = normalizeEmail(want)
+	return func(input detection.Input) (string, string, bool) {
+		return matchIdentity(input, func(email string) bool { return email == want })
+	}
+}
+
+func emailRegex(pattern string) (matcher, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("email_regex: %w", err)
+	}
+	return func(input detection.Input) (string, string, bool) {
+		return matchIdentity(input, re.MatchString)
+	}, nil
+}
+
+func trailer(t Trailer) (matcher, error) {
+	key := strings.TrimSpace(t.Key)
+	if key == "" {
+		return nil, errors.New("trailer key is required")
+	}
+	var value *regexp.Regexp
+	if t.Value != "" {
+		var err error
+		value, err = regexp.Compile(t.Value)
+		if err != nil {
+			return nil, fmt.Errorf("trailer value: %w", err)
+		}
+	}
+
+	return func(input detection.Input) (string, string, bool) {
+		for _, v := range input.MessageTrailers().Values(key) {
+			if value == nil || value.MatchString(v) {
+				return fmt.Sprintf("trailer %s: %s", key, v), "", true
+			}
+		}
+		return "", "", false
+	}, nil
+}
+
+func messageRegex(pattern string) (matcher, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("message_regex: %w", err)
+	}
+	return func(input detection.Input) (string, string, bool) {
+		if input.CommitMessage == "" || !re.MatchString(input.CommitMessage) {
+			return "", "", false
+		}
+		return "commit message matches pattern", "", true
+	}, nil
+}
+
+func textRegex(pattern string) (matcher, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("text_regex: %w", err)
+	}
+	return func(input detection.Input) (string, string, bool) {
+		if input.Text == "" || !re.MatchString(input.Text) {
+			return "", "", false
+		}
+		return "text matches pattern", "", true
+	}, nil
+}
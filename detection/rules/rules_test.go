@@ -0,0 +1,193 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chaoss/ai-detection-action/detection"
+)
+
+const sampleYAML = `
+rules:
+  - name: acme-bot
+    tool: Acme Assistant
+    confidence: high
+    email: Assistant@Acme.Internal
+  - tool: Acme Assistant
+    confidence: high
+    email_regex: '^\d+\+acme-assistant\[bot\]@users\.noreply\.github\.com$'
+  - tool: House AI
+    confidence: medium
+    trailer:
+      key: House-AI
+      value: '^(yes|true)$'
+  - tool: House AI
+    confidence: medium
+    message_regex: '(?m)^\[house-ai\]'
+  - tool: House AI
+    confidence: low
+    text_regex: '(?i)\bhouse ai\b'
+  - tool: claude code
+    confidence: high
+    email: claude-agent@acme.internal
+`
+
+func TestDetect(t *testing.T) {
+	d, err := ParseYAML([]byte(sampleYAML))
+	if err != nil {
+		t.Fatalf("ParseYAML: %v", err)
+	}
+
+	tests := []struct {
+		name           string
+		input          detection.Input
+		wantTools      []string
+		wantConfidence []detection.Confidence
+	}{
+		{
+			name:           "exact email, case-insensitive",
+			input:          detection.Input{CommitEmail: "  assistant@acme.internal "},
+			wantTools:      []string{"Acme Assistant"},
+			wantConfidence: []detection.Confidence{detection.ConfidenceHigh},
+		},
+		{
+			name:           "exact email on author only",
+			input:          detection.Input{AuthorEmail: "assistant@acme.internal", CommitEmail: "noreply@github.com"},
+			wantTools:      []string{"Acme Assistant"},
+			wantConfidence: []detection.Confidence{detection.ConfidenceHigh},
+		},
+		{
+			name:           "email regex",
+			input:          detection.Input{CommitEmail: "12345+acme-assistant[bot]@users.noreply.github.com"},
+			wantTools:      []string{"Acme Assistant"},
+			wantConfidence: []detection.Confidence{detection.ConfidenceHigh},
+		},
+		{
+			name:           "trailer with matching value",
+			input:          detection.Input{CommitMessage: "fix: thing\n\nhouse-ai: yes"},
+			wantTools:      []string{"House AI"},
+			wantConfidence: []detection.Confidence{detection.ConfidenceMedium},
+		},
+		{
+			name:      "trailer-looking line in the title paragraph",
+			input:     detection.Input{CommitMessage: "House-AI: yes"},
+			wantTools: nil,
+		},
+		{
+			name:      "trailer with non-matching value",
+			input:     detection.Input{CommitMessage: "fix: thing\n\nHouse-AI: no"},
+			wantTools: nil,
+		},
+		{
+			name:           "message regex",
+			input:          detection.Input{CommitMessage: "[house-ai] generated refactor"},
+			wantTools:      []string{"House AI"},
+			wantConfidence: []detection.Confidence{detection.ConfidenceMedium},
+		},
+		{
+			name:           "text regex",
+			input:          detection.Input{Text: "Drafted with House AI"},
+			wantTools:      []string{"House AI"},
+			wantConfidence: []detection.Confidence{detection.ConfidenceLow},
+		},
+		{
+			name:           "catalog tool resolves to canonical name",
+			input:          detection.Input{CommitEmail: "claude-agent@acme.internal"},
+			wantTools:      []string{"Claude Code"},
+			wantConfidence: []detection.Confidence{detection.ConfidenceHigh},
+		},
+		{
+			name:      "no match",
+			input:     detection.Input{CommitEmail: "human@example.com", CommitMessage: "normal commit"},
+			wantTools: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings := d.Detect(tt.input)
+			if len(findings) != len(tt.wantTools) {
+				t.Fatalf("got %d findings, want %d: %+v", len(findings), len(tt.wantTools), findings)
+			}
+			for i, f := range findings {
+				if f.Tool != tt.wantTools[i] {
+					t.Errorf("tool = %q, want %q", f.Tool, tt.wantTools[i])
+				}
+				if f.Confidence != tt.wantConfidence[i] {
+					t.Errorf("confidence = %d, want %d", f.Confidence, tt.wantConfidence[i])
+				}
+				if f.Detector != "rules" {
+					t.Errorf("detector = %q, want %q", f.Detector, "rules")
+				}
+			}
+		})
+	}
+}
+
+func TestLoadJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.json")
+	data := `{"rules": [{"tool": "Acme Assistant", "confidence": "2", "message_regex": "^acme:"}]}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	d, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	findings := d.Detect(detection.Input{CommitMessage: "acme: add endpoint"})
+	if len(findings) != 1 || findings[0].Confidence != detection.ConfidenceMedium {
+		t.Fatalf("unexpected findings: %+v", findings)
+	}
+	if findings[0].ToolID != "acme-assistant" {
+		t.Errorf("tool ID = %q, want %q", findings[0].ToolID, "acme-assistant")
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := map[string]string{
+		"missing tool":        "rules:\n  - confidence: high\n    email: a@b.c\n",
+		"bad confidence":      "rules:\n  - tool: X\n    confidence: certain\n    email: a@b.c\n",
+		"no matcher":          "rules:\n  - tool: X\n    confidence: high\n",
+		"two matchers":        "rules:\n  - tool: X\n    confidence: high\n    email: a@b.c\n    text_regex: x\n",
+		"bad regex":           "rules:\n  - tool: X\n    confidence: high\n    message_regex: '('\n",
+		"unknown field":       "rules:\n  - tool: X\n    confidence: high\n    emial: a@b.c\n",
+		"trailer missing key": "rules:\n  - tool: X\n    confidence: high\n    trailer:\n      value: x\n",
+		"empty file":          "",
+		"only comments":       "# rules go here\n",
+		"empty rules list":    "rules: []\n",
+	}
+
+	for name, doc := range cases {
+		if _, err := ParseYAML([]byte(doc)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+	if _, err := ParseJSON([]byte(`{"rules": []}`)); err == nil {
+		t.Error("JSON without rules: expected error")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	fromYAML, err := ParseYAML([]byte("rules:\n  - tool: Acme Assistant\n    confidence: medium\n    message_regex: '^acme:'\n"))
+	if err != nil {
+		t.Fatalf("ParseYAML: %v", err)
+	}
+	fromJSON, err := ParseJSON([]byte(`{"rules": [{"tool": "Acme Assistant", "confidence": "medium", "message_regex": "^acme:"}]}`))
+	if err != nil {
+		t.Fatalf("ParseJSON: %v", err)
+	}
+	other, err := ParseYAML([]byte(sampleYAML))
+	if err != nil {
+		t.Fatalf("ParseYAML: %v", err)
+	}
+
+	if fromYAML.Fingerprint() == "" || fromYAML.Fingerprint() != fromJSON.Fingerprint() {
+		t.Errorf("same rules in YAML and JSON: fingerprints %q and %q", fromYAML.Fingerprint(), fromJSON.Fingerprint())
+	}
+	if fromYAML.Fingerprint() == other.Fingerprint() {
+		t.Error("different rules share a fingerprint")
+	}
+}
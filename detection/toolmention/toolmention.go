@@ -6,46 +6,30 @@ import (
 	"strings"
 
 	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/detection/catalog"
 )
 
-// toolPatterns maps AI tool names to compiled word-boundary regexes.
+// toolPatterns pairs catalog tools with a word-boundary regex over their mentions.
 var toolPatterns []struct {
-	name    string
+	tool    catalog.Tool
 	pattern *regexp.Regexp
 }
 
 func init() {
-	tools := []string{
-		"Claude Code",
-		"Claude",
-		"GitHub Copilot",
-		"Copilot",
-		"Cursor",
-		"Aider",
-		"OpenAI Codex",
-		"Codex",
-		"Gemini Code Assist",
-		"Amazon Q Developer",
-		"Amazon Q",
-		"Devin",
-		"Cline",
-		"Continue.dev",
-		"Sourcegraph Cody",
-		"Cody",
-		"JetBrains AI",
-		"CodeRabbit",
-		"ChatGPT",
-		"GPT-4",
-		"Windsurf",
-	}
+	for _, tool := range catalog.All() {
+		if len(tool.Mentions) == 0 {
+			continue
+		}
 
-	for _, name := range tools {
-		escaped := regexp.QuoteMeta(name)
-		pattern := regexp.MustCompile(`(?i)\b` + escaped + `\b`)
+		escaped := make([]string, len(tool.Mentions))
+		for i, m := range tool.Mentions {
+			escaped[i] = regexp.QuoteMeta(m)
+		}
+		pattern := regexp.MustCompile(`(?i)\b(?:` + strings.Join(escaped, "|") + `)\b`)
 		toolPatterns = append(toolPatterns, struct {
-			name    string
+			tool    catalog.Tool
 			pattern *regexp.Regexp
-		}{name: name, pattern: pattern})
+		}{tool: tool, pattern: pattern})
 	}
 }
 
@@ -53,6 +37,8 @@ type Detector struct{}
 
 func (d *Detector) Name() string { return "toolmention" }
 
+func (d *Detector) ConcurrencySafe() bool { return true }
+
 func (d *Detector) Detect(input detection.Input) []detection.Finding {
 	text := input.Text
 	if input.CommitMessage != "" {
@@ -68,20 +54,15 @@ func (d *Detector) Detect(input detection.Input) []detection.Finding {
 	}
 
 	var findings []detection.Finding
-	seen := map[string]bool{}
-
 	for _, tp := range toolPatterns {
-		if seen[tp.name] {
-			continue
-		}
 		if tp.pattern.MatchString(text) {
 			findings = append(findings, detection.Finding{
 				Detector:   d.Name(),
-				Tool:       tp.name,
+				Tool:       tp.tool.Name,
+				ToolID:     tp.tool.ID,
 				Confidence: detection.ConfidenceLow,
-				Detail:     fmt.Sprintf("text mentions %s", tp.name),
+				Detail:     fmt.Sprintf("text mentions %s", tp.tool.Name),
 			})
-			seen[tp.name] = true
 		}
 	}
 
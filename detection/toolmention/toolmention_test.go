@@ -27,7 +27,7 @@ func TestDetect(t *testing.T) {
 		{
 			name:      "Copilot mention",
 			input:     detection.Input{Text: "GitHub Copilot helped with this"},
-			wantTools: []string{"GitHub Copilot", "Copilot"},
+			wantTools: []string{"GitHub Copilot"},
 		},
 		{
 			name:      "multiple tools mentioned",
@@ -74,6 +74,11 @@ func TestDetect(t *testing.T) {
 			input:     detection.Input{Text: "Written with Windsurf IDE"},
 			wantTools: []string{"Windsurf"},
 		},
+		{
+			name:      "bare alias resolves to canonical name",
+			input:     detection.Input{Text: "Copilot suggested this and Cody reviewed it"},
+			wantTools: []string{"GitHub Copilot", "Sourcegraph Cody"},
+		},
 		{
 			name:      "Devin mention",
 			input:     detection.Input{Text: "Devin created this PR"},
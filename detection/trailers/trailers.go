@@ -0,0 +1,225 @@
+// Package trailers parses the trailer block of a commit message the way
+// git interpret-trailers does.
+//
+// The trailer block is the last paragraph of the message, provided it is not
+// also the first paragraph (the title) and either every line in it is a
+// trailer or continuation line, or it contains a git-generated trailer such
+// as Signed-off-by and at least 25% of its lines are trailers. Continuation
+// lines (lines starting with whitespace) are unfolded into the preceding
+// trailer's value, CRLF line endings are accepted, and keys are compared
+// case-insensitively.
+package trailers
+
+import (
+	"strings"
+)
+
+// DefaultSeparators is git's default trailer.separators value.
+const DefaultSeparators = ":"
+
+// commentPrefix is git's default core.commentChar. Comment lines are skipped
+// when locating and parsing the trailer block.
+const commentPrefix = "#"
+
+// gitGeneratedPrefixes mark a paragraph as a trailer block even when it also
+// contains ordinary text.
+var gitGeneratedPrefixes = []string{
+	"Signed-off-by: ",
+	"(cherry picked from commit ",
+}
+
+// Trailer is one key/value pair from a trailer block. Value is unfolded and
+// trimmed.
+type Trailer struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// Trailers is a parsed trailer block, in message order.
+type Trailers []Trailer
+
+// Parse returns the trailers of message using the default ":" separator.
+func Parse(message string) Trailers {
+	return ParseWithSeparators(message, DefaultSeparators)
+}
+
+// ParseWithSeparators returns the trailers of message, accepting any
+// character in separators between key and value, like git's
+// trailer.separators setting.
+func ParseWithSeparators(message, separators string) Trailers {
+	if separators == "" {
+		separators = DefaultSeparators
+	}
+
+	lines := strings.Split(strings.ReplaceAll(message, "\r\n", "\n"), "\n")
+	start := blockStart(lines, separators)
+	if start < 0 {
+		return nil
+	}
+
+	var out Trailers
+	// folding reports whether a continuation line would extend the last
+	// trailer, i.e. no ordinary text line has come between them.
+	folding := false
+	for _, line := range lines[start:] {
+		if strings.HasPrefix(line, commentPrefix) || isBlank(line) {
+			continue
+		}
+		if isSpace(line[0]) {
+			if folding {
+				last := &out[len(out)-1]
+				last.Value = strings.TrimSpace(last.Value + " " + strings.TrimSpace(line))
+			}
+			continue
+		}
+		pos := findSeparator(line, separators)
+		if pos < 1 {
+			folding = false
+			continue
+		}
+		out = append(out, Trailer{
+			Key:   strings.TrimSpace(line[:pos]),
+			Value: strings.TrimSpace(line[pos+1:]),
+		})
+		folding = true
+	}
+	return out
+}
+
+// blockStart returns the index of the first line of the trailer block, or -1
+// if the message has none. It mirrors find_trailer_block_start in git's
+// trailer.c.
+func blockStart(lines []string, separators string) int {
+	// The first paragraph is the title and cannot be trailers.
+	endOfTitle := len(lines)
+	for i, line := range lines {
+		if strings.HasPrefix(line, commentPrefix) {
+			continue
+		}
+		if isBlank(line) {
+			endOfTitle = i
+			break
+		}
+	}
+
+	onlySpaces := true
+	recognizedPrefix := false
+	trailerLines, nonTrailerLines, possibleContinuationLines := 0, 0, 0
+
+	for i := len(lines) - 1; i >= endOfTitle; i-- {
+		line := lines[i]
+
+		if strings.HasPrefix(line, commentPrefix) {
+			nonTrailerLines += possibleContinuationLines
+			possibleContinuationLines = 0
+			continue
+		}
+		if isBlank(line) {
+			if onlySpaces {
+				continue
+			}
+			nonTrailerLines += possibleContinuationLines
+			if recognizedPrefix && trailerLines*3 >= nonTrailerLines {
+				return i + 1
+			}
+			if trailerLines > 0 && nonTrailerLines == 0 {
+				return i + 1
+			}
+			return -1
+		}
+		onlySpaces = false
+
+		if hasGitGeneratedPrefix(line) {
+			trailerLines++
+			possibleContinuationLines = 0
+			recognizedPrefix = true
+			continue
+		}
+
+		switch {
+		case findSeparator(line, separators) >= 1 && !isSpace(line[0]):
+			trailerLines++
+			possibleContinuationLines = 0
+		case isSpace(line[0]):
+			possibleContinuationLines++
+		default:
+			nonTrailerLines++
+			nonTrailerLines += possibleContinuationLines
+			possibleContinuationLines = 0
+		}
+	}
+
+	return -1
+}
+
+// findSeparator returns the position of the key/value separator in line, or
+// -1 if line does not look like a trailer. Keys are made of alphanumerics and
+// '-', optionally followed by whitespace before the separator.
+func findSeparator(line, separators string) int {
+	whitespaceFound := false
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		if strings.IndexByte(separators, c) >= 0 {
+			return i
+		}
+		if !whitespaceFound && (isAlnum(c) || c == '-') {
+			continue
+		}
+		if i != 0 && (c == ' ' || c == '\t') {
+			whitespaceFound = true
+			continue
+		}
+		break
+	}
+	return -1
+}
+
+func hasGitGeneratedPrefix(line string) bool {
+	for _, p := range gitGeneratedPrefixes {
+		if strings.HasPrefix(line, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'
+}
+
+func isAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// Values returns the values of every trailer whose key matches key
+// case-insensitively.
+func (t Trailers) Values(key string) []string {
+	var out []string
+	for _, tr := range t {
+		if strings.EqualFold(tr.Key, key) {
+			out = append(out, tr.Value)
+		}
+	}
+	return out
+}
+
+// Get returns the value of the first trailer whose key matches key
+// case-insensitively.
+func (t Trailers) Get(key string) (string, bool) {
+	for _, tr := range t {
+		if strings.EqualFold(tr.Key, key) {
+			return tr.Value, true
+		}
+	}
+	return "", false
+}
+
+// Has reports whether a trailer with the given key is present.
+func (t Trailers) Has(key string) bool {
+	_, ok := t.Get(key)
+	return ok
+}
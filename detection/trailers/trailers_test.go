@@ -0,0 +1,139 @@
+package trailers
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    Trailers
+	}{
+		{
+			name:    "simple block",
+			message: "fix: thing\n\nBody text.\n\nCo-authored-by: A <a@example.com>\nReviewed-by: B <b@example.com>\n",
+			want: Trailers{
+				{Key: "Co-authored-by", Value: "A <a@example.com>"},
+				{Key: "Reviewed-by", Value: "B <b@example.com>"},
+			},
+		},
+		{
+			name:    "title paragraph is never a trailer block",
+			message: "subject\nKey: v",
+			want:    nil,
+		},
+		{
+			name:    "trailer-looking line in the body is ignored",
+			message: "subject\n\nKey: v\n\nThis is the last paragraph.",
+			want:    nil,
+		},
+		{
+			name:    "folded continuation lines are unfolded",
+			message: "subject\n\nKey: first\n  second\n\tthird\nOther: x",
+			want: Trailers{
+				{Key: "Key", Value: "first second third"},
+				{Key: "Other", Value: "x"},
+			},
+		},
+		{
+			name:    "CRLF line endings",
+			message: "subject\r\n\r\nEntire-Metadata: abc\r\nOther: x\r\n",
+			want: Trailers{
+				{Key: "Entire-Metadata", Value: "abc"},
+				{Key: "Other", Value: "x"},
+			},
+		},
+		{
+			name:    "trailing blank lines are skipped",
+			message: "subject\n\nKey: v\n\n\n",
+			want:    Trailers{{Key: "Key", Value: "v"}},
+		},
+		{
+			name:    "whitespace before separator",
+			message: "subject\n\nKey : v",
+			want:    Trailers{{Key: "Key", Value: "v"}},
+		},
+		{
+			name:    "mixed paragraph without git-generated trailer",
+			message: "subject\n\nsome text\nKey: a",
+			want:    nil,
+		},
+		{
+			name:    "mixed paragraph with Signed-off-by and enough trailers",
+			message: "subject\n\nsome text\nSigned-off-by: A <a@example.com>\nKey: v",
+			want: Trailers{
+				{Key: "Signed-off-by", Value: "A <a@example.com>"},
+				{Key: "Key", Value: "v"},
+			},
+		},
+		{
+			name:    "Signed-off-by under 25 percent of the paragraph",
+			message: "subject\n\nsome text\nSigned-off-by: a\nnot trailer\nnot\nnot\n",
+			want:    nil,
+		},
+		{
+			name:    "continuation does not attach across a text line",
+			message: "subject\n\nSigned-off-by: a\nKey: v\n(cherry picked from commit abc)\n  folded",
+			want: Trailers{
+				{Key: "Signed-off-by", Value: "a"},
+				{Key: "Key", Value: "v"},
+			},
+		},
+		{
+			name:    "comment lines are skipped",
+			message: "subject\n\nKey: v\n# Please enter the commit message",
+			want:    Trailers{{Key: "Key", Value: "v"}},
+		},
+		{
+			name:    "key with spaces is not a trailer",
+			message: "subject\n\nNot a key: v",
+			want:    nil,
+		},
+		{
+			name:    "empty message",
+			message: "",
+			want:    nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Parse(tt.message)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseWithSeparators(t *testing.T) {
+	got := ParseWithSeparators("subject\n\nKey: v\n  cont\nFoo #x\nBar= y", ":#=")
+	want := Trailers{
+		{Key: "Key", Value: "v cont"},
+		{Key: "Foo", Value: "x"},
+		{Key: "Bar", Value: "y"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseWithSeparators() = %#v, want %#v", got, want)
+	}
+
+	if got := Parse("subject\n\nFoo #x"); got != nil {
+		t.Errorf("Parse with default separators = %#v, want nil", got)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	tr := Parse("subject\n\nCo-Authored-By: A <a@x>\nco-authored-by: B <b@x>\nKey: v")
+
+	if got := tr.Values("CO-AUTHORED-BY"); !reflect.DeepEqual(got, []string{"A <a@x>", "B <b@x>"}) {
+		t.Errorf("Values = %v", got)
+	}
+	if v, ok := tr.Get("key"); !ok || v != "v" {
+		t.Errorf("Get(key) = %q, %v", v, ok)
+	}
+	if tr.Has("Missing") {
+		t.Error("Has(Missing) = true, want false")
+	}
+}
@@ -0,0 +1,74 @@
+package gitops
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// FileBlame records, for each line of a file, the commit that last changed
+// it.
+type FileBlame struct {
+	Path    string
+	Commits []string // one hash per line, in line order
+}
+
+// BlameFiles blames the files in the tree of the commit hash, in tree order,
+// skipping binary files and those for which keep returns false. A nil keep
+// blames every file.
+func BlameFiles(repoPath, hash string, keep func(path string) bool) iter.Seq2[FileBlame, error] {
+	return func(yield func(FileBlame, error) bool) {
+		repo, err := git.PlainOpen(repoPath)
+		if err != nil {
+			yield(FileBlame{}, fmt.Errorf("opening repo: %w", err))
+			return
+		}
+		commit, err := repo.CommitObject(plumbing.NewHash(hash))
+		if err != nil {
+			yield(FileBlame{}, fmt.Errorf("reading commit %s: %w", hash, err))
+			return
+		}
+		tree, err := commit.Tree()
+		if err != nil {
+			yield(FileBlame{}, fmt.Errorf("reading tree of %s: %w", hash, err))
+			return
+		}
+
+		var paths []string
+		err = tree.Files().ForEach(func(f *object.File) error {
+			if keep != nil && !keep(f.Name) {
+				return nil
+			}
+			binary, err := f.IsBinary()
+			if err != nil {
+				return err
+			}
+			if !binary {
+				paths = append(paths, f.Name)
+			}
+			return nil
+		})
+		if err != nil {
+			yield(FileBlame{}, fmt.Errorf("listing files of %s: %w", hash, err))
+			return
+		}
+
+		for _, path := range paths {
+			result, err := git.Blame(commit, path)
+			if err != nil {
+				yield(FileBlame{}, fmt.Errorf("blaming %s: %w", path, err))
+				return
+			}
+			fb := FileBlame{Path: path, Commits: make([]string, len(result.Lines))}
+			for i, line := range result.Lines {
+				fb.Commits[i] = line.Hash.String()
+			}
+			if !yield(fb, nil) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package gitops
+
+import "testing"
+
+func TestBlameFiles(t *testing.T) {
+	dir, hashes := initTestRepo(t)
+
+	var got []FileBlame
+	for fb, err := range BlameFiles(dir, hashes[2], nil) {
+		if err != nil {
+			t.Fatalf("BlameFiles: %v", err)
+		}
+		got = append(got, fb)
+	}
+	if len(got) != 3 {
+		t.Fatalf("blamed %d files, want 3", len(got))
+	}
+	// Each fixture file is one line written by its own commit.
+	for i, fb := range got {
+		if fb.Path != "file"+string(rune('0'+i))+".txt" || len(fb.Commits) != 1 || fb.Commits[0] != hashes[i] {
+			t.Errorf("file %d = %+v, want one line from %s", i, fb, hashes[i][:7])
+		}
+	}
+
+	// At the second commit only two files exist, and keep narrows them.
+	var paths []string
+	for fb, err := range BlameFiles(dir, hashes[1], func(path string) bool { return path != "file0.txt" }) {
+		if err != nil {
+			t.Fatalf("BlameFiles: %v", err)
+		}
+		paths = append(paths, fb.Path)
+	}
+	if len(paths) != 1 || paths[0] != "file1.txt" {
+		t.Errorf("paths = %v, want [file1.txt]", paths)
+	}
+}
+
+func TestBlameFilesInvalidCommit(t *testing.T) {
+	dir, _ := initTestRepo(t)
+	for _, err := range BlameFiles(dir, "0000000000000000000000000000000000000000", nil) {
+		if err == nil {
+			t.Fatal("expected error for a missing commit")
+		}
+	}
+}
@@ -1,29 +1,116 @@
 package gitops
 
 import (
+	"errors"
 	"fmt"
-	"strings"
+	"iter"
+	"time"
 
 	"github.com/go-git/go-git/v5"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/utils/merkletrie"
 )
 
-// Commit holds the fields detectors care about from a git commit.
+// Commit holds the fields detectors and reports care about from a git commit.
 type Commit struct {
 	Hash           string
+	AuthorName     string
 	AuthorEmail    string
+	CommitterName  string
 	CommitterEmail string
+	AuthorTime     time.Time
+	CommitTime     time.Time
 	Message        string
+	Files          []FileChange // Files changed relative to the first parent
+	AuthorshipLog  []byte       // Note under AuthorshipNotesRef, if any
 }
 
-func commitFromObject(c *object.Commit) Commit {
+// AuthorshipNotesRef is where git-ai and compatible tools record, as a git
+// note on each commit, which of its lines an AI agent wrote.
+const AuthorshipNotesRef = "refs/notes/ai"
+
+// File change actions.
+const (
+	FileAdded    = "added"
+	FileModified = "modified"
+	FileDeleted  = "deleted"
+)
+
+// FileChange is a path a commit added, modified or deleted.
+type FileChange struct {
+	Path   string
+	Action string
+}
+
+func commitFromObject(c *object.Commit) (Commit, error) {
+	files, err := changedFiles(c)
+	if err != nil {
+		return Commit{}, fmt.Errorf("diffing commit %s: %w", c.Hash, err)
+	}
+
 	return Commit{
 		Hash:           c.Hash.String(),
+		AuthorName:     c.Author.Name,
 		AuthorEmail:    c.Author.Email,
+		CommitterName:  c.Committer.Name,
 		CommitterEmail: c.Committer.Email,
+		AuthorTime:     c.Author.When,
+		CommitTime:     c.Committer.When,
 		Message:        c.Message,
+		Files:          files,
+	}, nil
+}
+
+// changedFiles diffs c against its parent. Like git log --name-status, merge
+// commits report no files, and a root commit reports every file as added. In
+// a shallow clone whose parent is missing the files are unknown and nil is
+// returned.
+func changedFiles(c *object.Commit) ([]FileChange, error) {
+	if c.NumParents() > 1 {
+		return nil, nil
+	}
+
+	tree, err := c.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	var parentTree *object.Tree
+	if c.NumParents() == 1 {
+		parent, err := c.Parent(0)
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if parentTree, err = parent.Tree(); err != nil {
+			return nil, err
+		}
+	}
+
+	changes, err := object.DiffTree(parentTree, tree)
+	if err != nil {
+		return nil, err
 	}
+
+	files := make([]FileChange, 0, len(changes))
+	for _, ch := range changes {
+		action, err := ch.Action()
+		if err != nil {
+			return nil, err
+		}
+		switch action {
+		case merkletrie.Insert:
+			files = append(files, FileChange{Path: ch.To.Name, Action: FileAdded})
+		case merkletrie.Delete:
+			files = append(files, FileChange{Path: ch.From.Name, Action: FileDeleted})
+		default:
+			files = append(files, FileChange{Path: ch.To.Name, Action: FileModified})
+		}
+	}
+	return files, nil
 }
 
 // GetCommit reads a single commit by hash from the repository at repoPath.
@@ -39,123 +126,188 @@ func GetCommit(repoPath string, hash string) (Commit, error) {
 		return Commit{}, fmt.Errorf("reading commit %s: %w", hash, err)
 	}
 
-	return commitFromObject(c), nil
+	authorship, err := readNotes(repo, AuthorshipNotesRef)
+	if err != nil {
+		return Commit{}, err
+	}
+	return readCommit(c, authorship)
+}
+
+// Tree is the file tree of a single commit.
+type Tree struct {
+	Commit string // Hash of the commit the tree belongs to
+	tree   *object.Tree
 }
 
-// ListCommits returns commits in the given range. The range format is "BASE..HEAD"
-// where BASE and HEAD are commit hashes or ref names. If commitRange is empty,
-// all commits reachable from HEAD are returned.
-func ListCommits(repoPath string, commitRange string) ([]Commit, error) {
+// HeadTree returns the tree of the commit HEAD points to in the repository at
+// repoPath.
+func HeadTree(repoPath string) (*Tree, error) {
 	repo, err := git.PlainOpen(repoPath)
 	if err != nil {
 		return nil, fmt.Errorf("opening repo: %w", err)
 	}
 
-	if commitRange == "" {
-		return listAllCommits(repo)
-	}
-
-	parts := strings.SplitN(commitRange, "..", 2)
-	if len(parts) != 2 {
-		return nil, fmt.Errorf("invalid range format %q, expected BASE..HEAD", commitRange)
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("getting HEAD: %w", err)
 	}
 
-	baseName := strings.TrimSpace(parts[0])
-	headName := strings.TrimSpace(parts[1])
-
-	baseHash, err := resolveRef(repo, baseName)
+	c, err := repo.CommitObject(head.Hash())
 	if err != nil {
-		return nil, fmt.Errorf("resolving base %q: %w", baseName, err)
+		return nil, fmt.Errorf("reading commit %s: %w", head.Hash(), err)
 	}
 
-	headHash, err := resolveRef(repo, headName)
+	tree, err := c.Tree()
 	if err != nil {
-		return nil, fmt.Errorf("resolving head %q: %w", headName, err)
+		return nil, fmt.Errorf("reading tree of %s: %w", c.Hash, err)
 	}
 
-	return listCommitRange(repo, baseHash, headHash)
+	return &Tree{Commit: c.Hash.String(), tree: tree}, nil
 }
 
-func resolveRef(repo *git.Repository, name string) (plumbing.Hash, error) {
-	// Try as a full hash first
-	if plumbing.IsHash(name) {
-		return plumbing.NewHash(name), nil
+// Files returns the path of every file in the tree, in tree order.
+func (t *Tree) Files() ([]string, error) {
+	var paths []string
+	err := t.tree.Files().ForEach(func(f *object.File) error {
+		paths = append(paths, f.Name)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking tree: %w", err)
 	}
+	return paths, nil
+}
 
-	// Try as a reference name
-	ref, err := repo.Reference(plumbing.ReferenceName(name), true)
-	if err == nil {
-		return ref.Hash(), nil
+// ReadFile returns the contents of the file at path.
+func (t *Tree) ReadFile(path string) (string, error) {
+	f, err := t.tree.File(path)
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", path, err)
 	}
+	return f.Contents()
+}
 
-	// Try common ref prefixes
-	for _, prefix := range []string{"refs/heads/", "refs/tags/", "refs/remotes/"} {
-		ref, err = repo.Reference(plumbing.ReferenceName(prefix+name), true)
-		if err == nil {
-			return ref.Hash(), nil
+// ListCommits returns commits in the given range, newest first. The range
+// uses git's revision syntax: "BASE..HEAD", "A...B", a single revision for
+// everything reachable from it, and "^REV" terms to exclude more history,
+// where each revision may be a hash (full or abbreviated), a ref name, or
+// an expression such as HEAD~3 or main@{upstream}. If commitRange is empty,
+// all commits reachable from HEAD are returned.
+//
+// ListCommits holds every commit in memory; use Commits to process large
+// histories one commit at a time.
+func ListCommits(repoPath string, commitRange string) ([]Commit, error) {
+	var commits []Commit
+	for c, err := range Commits(repoPath, commitRange) {
+		if err != nil {
+			return nil, err
 		}
+		commits = append(commits, c)
 	}
+	return commits, nil
+}
 
-	// Try as abbreviated hash by iterating objects (go-git doesn't support short hashes natively)
-	return plumbing.Hash{}, fmt.Errorf("cannot resolve %q to a commit", name)
+// Commits returns an iterator over the commits in commitRange, in the same
+// order and with the same range syntax as ListCommits. Each commit is read
+// and diffed only when the iteration reaches it. An error is yielded once,
+// with a zero Commit, and ends the iteration.
+func Commits(repoPath string, commitRange string) iter.Seq2[Commit, error] {
+	return CommitsSkipping(repoPath, commitRange, nil)
 }
 
-func listAllCommits(repo *git.Repository) ([]Commit, error) {
-	head, err := repo.Head()
-	if err != nil {
-		return nil, fmt.Errorf("getting HEAD: %w", err)
-	}
+// CommitsSkipping is Commits, except that a commit for which skip returns
+// true is yielded with only its Hash set, without being read or diffed. skip
+// is called once per commit, just before that commit is yielded; a nil skip
+// skips nothing.
+func CommitsSkipping(repoPath string, commitRange string, skip func(hash string) bool) iter.Seq2[Commit, error] {
+	return func(yield func(Commit, error) bool) {
+		repo, err := git.PlainOpen(repoPath)
+		if err != nil {
+			yield(Commit{}, fmt.Errorf("opening repo: %w", err))
+			return
+		}
 
-	iter, err := repo.Log(&git.LogOptions{From: head.Hash()})
-	if err != nil {
-		return nil, fmt.Errorf("creating log iterator: %w", err)
-	}
+		index := openCommitIndex(repo)
+		defer index.Close()
 
-	var commits []Commit
-	err = iter.ForEach(func(c *object.Commit) error {
-		commits = append(commits, commitFromObject(c))
-		return nil
-	})
-	if err != nil {
-		return nil, fmt.Errorf("iterating commits: %w", err)
-	}
+		r, err := parseRange(repo, index, commitRange)
+		if err != nil {
+			yield(Commit{}, err)
+			return
+		}
+		authorship, err := readNotes(repo, AuthorshipNotesRef)
+		if err != nil {
+			yield(Commit{}, err)
+			return
+		}
 
-	return commits, nil
+		for node, err := range walkRange(r) {
+			if err != nil {
+				yield(Commit{}, err)
+				return
+			}
+			if hash := node.ID().String(); skip != nil && skip(hash) {
+				if !yield(Commit{Hash: hash}, nil) {
+					return
+				}
+				continue
+			}
+			o, err := node.Commit()
+			if err != nil {
+				yield(Commit{}, fmt.Errorf("reading commit %s: %w", node.ID(), err))
+				return
+			}
+			c, err := readCommit(o, authorship)
+			if !yield(c, err) || err != nil {
+				return
+			}
+		}
+	}
 }
 
-func listCommitRange(repo *git.Repository, base, head plumbing.Hash) ([]Commit, error) {
-	// Collect all commits reachable from head
-	headCommit, err := repo.CommitObject(head)
-	if err != nil {
-		return nil, fmt.Errorf("reading head commit: %w", err)
-	}
+// CommitsByHash is CommitsSkipping for the commits named by hashes, in that
+// order, rather than a range: their ancestors are not walked.
+func CommitsByHash(repoPath string, hashes []string, skip func(hash string) bool) iter.Seq2[Commit, error] {
+	return func(yield func(Commit, error) bool) {
+		repo, err := git.PlainOpen(repoPath)
+		if err != nil {
+			yield(Commit{}, fmt.Errorf("opening repo: %w", err))
+			return
+		}
+		authorship, err := readNotes(repo, AuthorshipNotesRef)
+		if err != nil {
+			yield(Commit{}, err)
+			return
+		}
 
-	// Collect ancestors of base to exclude
-	baseExclude := map[plumbing.Hash]bool{base: true}
-	baseCommit, err := repo.CommitObject(base)
-	if err != nil {
-		return nil, fmt.Errorf("reading base commit: %w", err)
+		for _, hash := range hashes {
+			if skip != nil && skip(hash) {
+				if !yield(Commit{Hash: hash}, nil) {
+					return
+				}
+				continue
+			}
+			o, err := repo.CommitObject(plumbing.NewHash(hash))
+			if err != nil {
+				yield(Commit{}, fmt.Errorf("reading commit %s: %w", hash, err))
+				return
+			}
+			c, err := readCommit(o, authorship)
+			if !yield(c, err) || err != nil {
+				return
+			}
+		}
 	}
+}
 
-	baseIter := object.NewCommitIterCTime(baseCommit, nil, nil)
-	_ = baseIter.ForEach(func(c *object.Commit) error {
-		baseExclude[c.Hash] = true
-		return nil
-	})
-
-	// Walk from head, collecting commits not in base's ancestry
-	var commits []Commit
-	headIter := object.NewCommitIterCTime(headCommit, nil, nil)
-	err = headIter.ForEach(func(c *object.Commit) error {
-		if baseExclude[c.Hash] {
-			return nil
-		}
-		commits = append(commits, commitFromObject(c))
-		return nil
-	})
+// readCommit converts o, adding its authorship log.
+func readCommit(o *object.Commit, authorship *Notes) (Commit, error) {
+	c, err := commitFromObject(o)
 	if err != nil {
-		return nil, fmt.Errorf("iterating commits: %w", err)
+		return Commit{}, err
 	}
-
-	return commits, nil
+	if c.AuthorshipLog, _, err = authorship.Get(c.Hash); err != nil {
+		return Commit{}, err
+	}
+	return c, nil
 }
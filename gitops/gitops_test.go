@@ -3,6 +3,7 @@ package gitops
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -66,6 +67,9 @@ func TestGetCommit(t *testing.T) {
 	if c.AuthorEmail != "test@example.com" {
 		t.Errorf("author email = %q, want %q", c.AuthorEmail, "test@example.com")
 	}
+	if c.AuthorName != "Test" {
+		t.Errorf("author name = %q, want %q", c.AuthorName, "Test")
+	}
 }
 
 func TestGetCommitNotFound(t *testing.T) {
@@ -139,3 +143,165 @@ func TestListCommitsInvalidRepo(t *testing.T) {
 		t.Error("expected error for non-repo directory")
 	}
 }
+
+func TestGetCommitFiles(t *testing.T) {
+	dir, hashes := initTestRepo(t)
+
+	root, err := GetCommit(dir, hashes[0])
+	if err != nil {
+		t.Fatalf("GetCommit: %v", err)
+	}
+	if len(root.Files) != 1 || root.Files[0] != (FileChange{Path: "file0.txt", Action: FileAdded}) {
+		t.Errorf("root commit files = %+v, want file0.txt added", root.Files)
+	}
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file1.txt"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := wt.Add("file1.txt"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if _, err := wt.Remove("file2.txt"); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	hash, err := wt.Commit("change and delete", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now().Add(time.Hour)},
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	c, err := GetCommit(dir, hash.String())
+	if err != nil {
+		t.Fatalf("GetCommit: %v", err)
+	}
+	want := []FileChange{
+		{Path: "file1.txt", Action: FileModified},
+		{Path: "file2.txt", Action: FileDeleted},
+	}
+	if len(c.Files) != len(want) {
+		t.Fatalf("files = %+v, want %+v", c.Files, want)
+	}
+	for i := range want {
+		if c.Files[i] != want[i] {
+			t.Errorf("files[%d] = %+v, want %+v", i, c.Files[i], want[i])
+		}
+	}
+}
+
+func TestHeadTree(t *testing.T) {
+	dir, hashes := initTestRepo(t)
+
+	tree, err := HeadTree(dir)
+	if err != nil {
+		t.Fatalf("HeadTree: %v", err)
+	}
+	if tree.Commit != hashes[2] {
+		t.Errorf("commit = %q, want %q", tree.Commit, hashes[2])
+	}
+
+	files, err := tree.Files()
+	if err != nil {
+		t.Fatalf("Files: %v", err)
+	}
+	want := []string{"file0.txt", "file1.txt", "file2.txt"}
+	if strings.Join(files, ",") != strings.Join(want, ",") {
+		t.Errorf("files = %v, want %v", files, want)
+	}
+
+	contents, err := tree.ReadFile("file1.txt")
+	if err != nil {
+		t.Fatalf("ReadFile: %v", err)
+	}
+	if contents != "second commit" {
+		t.Errorf("contents = %q, want %q", contents, "second commit")
+	}
+
+	if _, err := tree.ReadFile("missing.txt"); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestCommitsIterator(t *testing.T) {
+	dir, hashes := initTestRepo(t)
+
+	var got []string
+	for c, err := range Commits(dir, "") {
+		if err != nil {
+			t.Fatalf("Commits: %v", err)
+		}
+		got = append(got, c.Hash)
+		if len(got) == 2 {
+			break
+		}
+	}
+	if len(got) != 2 || got[0] != hashes[2] || got[1] != hashes[1] {
+		t.Errorf("got %v, want the two newest commits", got)
+	}
+}
+
+func TestCommitsIteratorError(t *testing.T) {
+	n := 0
+	for _, err := range Commits(t.TempDir(), "") {
+		n++
+		if err == nil {
+			t.Error("expected error for non-repo directory")
+		}
+	}
+	if n != 1 {
+		t.Errorf("got %d values, want a single error", n)
+	}
+}
+
+func TestListCommitsShallowBoundary(t *testing.T) {
+	dir, hashes := initTestRepo(t)
+
+	// Drop the root commit's object, as a depth-2 shallow clone would.
+	root := filepath.Join(dir, ".git", "objects", hashes[0][:2], hashes[0][2:])
+	if err := os.Remove(root); err != nil {
+		t.Fatalf("remove object: %v", err)
+	}
+
+	commits, err := ListCommits(dir, "")
+	if err != nil {
+		t.Fatalf("ListCommits: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Errorf("got %d commits, want 2", len(commits))
+	}
+}
+
+func TestCommitsSkipping(t *testing.T) {
+	dir, hashes := initTestRepo(t)
+
+	var asked []string
+	skip := func(hash string) bool {
+		asked = append(asked, hash)
+		return hash == hashes[1]
+	}
+	var got []Commit
+	for c, err := range CommitsSkipping(dir, "", skip) {
+		if err != nil {
+			t.Fatalf("CommitsSkipping: %v", err)
+		}
+		got = append(got, c)
+	}
+
+	if len(got) != 3 || len(asked) != 3 {
+		t.Fatalf("got %d commits after %d skip calls, want 3 and 3", len(got), len(asked))
+	}
+	if got[1].Hash != hashes[1] || got[1].Message != "" || got[1].Files != nil {
+		t.Errorf("skipped commit = %+v, want only its hash", got[1])
+	}
+	if got[0].Message == "" || got[2].Message == "" {
+		t.Error("commits not skipped should be read in full")
+	}
+}
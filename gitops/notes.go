@@ -0,0 +1,270 @@
+package gitops
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// notesFanout is the number of notes above which WriteNotes files them under
+// two-character directories, as git notes does for large note trees.
+const notesFanout = 256
+
+// Notes holds the git notes under one notes ref, as git notes stores them: a
+// commit history whose tree maps the hash of each annotated object to a blob
+// holding its note. Note contents are read on demand.
+type Notes struct {
+	repo    *git.Repository
+	entries map[string]plumbing.Hash // annotated object -> note blob
+}
+
+// NotesRef expands a short notes ref name such as "ai-detection" to
+// refs/notes/ai-detection, as git notes --ref does. Full ref names are
+// returned unchanged.
+func NotesRef(ref string) string {
+	if strings.HasPrefix(ref, "refs/") {
+		return ref
+	}
+	return "refs/notes/" + ref
+}
+
+// ReadNotes reads the notes under ref. A ref that does not exist yet holds no
+// notes and is not an error.
+func ReadNotes(repoPath, ref string) (*Notes, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening repo: %w", err)
+	}
+	return readNotes(repo, plumbing.ReferenceName(NotesRef(ref)))
+}
+
+func readNotes(repo *git.Repository, ref plumbing.ReferenceName) (*Notes, error) {
+	n := &Notes{repo: repo, entries: map[string]plumbing.Hash{}}
+
+	tip, err := repo.Reference(ref, true)
+	if errors.Is(err, plumbing.ErrReferenceNotFound) {
+		return n, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", ref, err)
+	}
+	commit, err := repo.CommitObject(tip.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", ref, err)
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", ref, err)
+	}
+
+	// Note paths may be split into directories (ab/cdef...) at any depth;
+	// the object hash is the path with the separators removed.
+	walker := object.NewTreeWalker(tree, true, nil)
+	defer walker.Close()
+	for {
+		name, entry, err := walker.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s: %w", ref, err)
+		}
+		if !entry.Mode.IsFile() {
+			continue
+		}
+		hash := strings.ReplaceAll(name, "/", "")
+		if plumbing.IsHash(hash) {
+			n.entries[hash] = entry.Hash
+		}
+	}
+	return n, nil
+}
+
+// Len returns the number of notes.
+func (n *Notes) Len() int { return len(n.entries) }
+
+// Get returns the note attached to the object hash, and whether there is one.
+func (n *Notes) Get(hash string) ([]byte, bool, error) {
+	blobHash, ok := n.entries[hash]
+	if !ok {
+		return nil, false, nil
+	}
+	blob, err := n.repo.BlobObject(blobHash)
+	if err != nil {
+		return nil, false, fmt.Errorf("reading note for %s: %w", hash, err)
+	}
+	r, err := blob.Reader()
+	if err != nil {
+		return nil, false, fmt.Errorf("reading note for %s: %w", hash, err)
+	}
+	defer r.Close()
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, false, fmt.Errorf("reading note for %s: %w", hash, err)
+	}
+	return data, true, nil
+}
+
+// WriteNotes attaches notes, keyed by object hash, under ref, replacing any
+// note those objects already have and keeping all the others. The change is
+// recorded as a single commit on ref with the given message, like one git
+// notes add. The committer is the user.name and user.email configured for
+// the repository, if any.
+func WriteNotes(repoPath, ref string, notes map[string][]byte, message string) error {
+	if len(notes) == 0 {
+		return nil
+	}
+
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return fmt.Errorf("opening repo: %w", err)
+	}
+	refName := plumbing.ReferenceName(NotesRef(ref))
+
+	existing, err := readNotes(repo, refName)
+	if err != nil {
+		return err
+	}
+	entries := existing.entries
+	for hash, data := range notes {
+		if !plumbing.IsHash(hash) {
+			return fmt.Errorf("cannot attach a note to %q: not an object hash", hash)
+		}
+		blobHash, err := storeBlob(repo, data)
+		if err != nil {
+			return fmt.Errorf("writing note for %s: %w", hash, err)
+		}
+		entries[hash] = blobHash
+	}
+
+	treeHash, err := storeNotesTree(repo, entries)
+	if err != nil {
+		return fmt.Errorf("writing notes tree: %w", err)
+	}
+
+	sig := notesSignature(repo)
+	commit := &object.Commit{
+		Author:    sig,
+		Committer: sig,
+		Message:   message,
+		TreeHash:  treeHash,
+	}
+	old, err := repo.Reference(refName, true)
+	switch {
+	case err == nil:
+		commit.ParentHashes = []plumbing.Hash{old.Hash()}
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+		old = nil
+	default:
+		return fmt.Errorf("reading %s: %w", refName, err)
+	}
+
+	obj := repo.Storer.NewEncodedObject()
+	if err := commit.Encode(obj); err != nil {
+		return fmt.Errorf("writing notes commit: %w", err)
+	}
+	commitHash, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		return fmt.Errorf("writing notes commit: %w", err)
+	}
+
+	// Refuse to overwrite notes another writer added since we read them.
+	if err := repo.Storer.CheckAndSetReference(plumbing.NewHashReference(refName, commitHash), old); err != nil {
+		return fmt.Errorf("updating %s: %w", refName, err)
+	}
+	return nil
+}
+
+func storeBlob(repo *git.Repository, data []byte) (plumbing.Hash, error) {
+	obj := repo.Storer.NewEncodedObject()
+	obj.SetType(plumbing.BlobObject)
+	w, err := obj.Writer()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if _, err := w.Write(data); err != nil {
+		w.Close()
+		return plumbing.ZeroHash, err
+	}
+	if err := w.Close(); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return repo.Storer.SetEncodedObject(obj)
+}
+
+// storeNotesTree writes the tree for a set of notes: flat for small sets,
+// otherwise split on the first two hex digits of each object hash.
+func storeNotesTree(repo *git.Repository, entries map[string]plumbing.Hash) (plumbing.Hash, error) {
+	if len(entries) <= notesFanout {
+		return storeTree(repo, entries, nil)
+	}
+
+	dirs := map[string]map[string]plumbing.Hash{}
+	for hash, blob := range entries {
+		prefix := hash[:2]
+		if dirs[prefix] == nil {
+			dirs[prefix] = map[string]plumbing.Hash{}
+		}
+		dirs[prefix][hash[2:]] = blob
+	}
+	subtrees := map[string]plumbing.Hash{}
+	for prefix, files := range dirs {
+		h, err := storeTree(repo, files, nil)
+		if err != nil {
+			return plumbing.ZeroHash, err
+		}
+		subtrees[prefix] = h
+	}
+	return storeTree(repo, nil, subtrees)
+}
+
+// storeTree writes a tree of regular files and subdirectories.
+func storeTree(repo *git.Repository, files, dirs map[string]plumbing.Hash) (plumbing.Hash, error) {
+	tree := &object.Tree{}
+	for name, h := range files {
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: name, Mode: filemode.Regular, Hash: h})
+	}
+	for name, h := range dirs {
+		tree.Entries = append(tree.Entries, object.TreeEntry{Name: name, Mode: filemode.Dir, Hash: h})
+	}
+	// git orders entries as if directory names ended in a slash.
+	sortKey := func(e object.TreeEntry) string {
+		if e.Mode == filemode.Dir {
+			return e.Name + "/"
+		}
+		return e.Name
+	}
+	sort.Slice(tree.Entries, func(i, j int) bool {
+		return sortKey(tree.Entries[i]) < sortKey(tree.Entries[j])
+	})
+
+	obj := repo.Storer.NewEncodedObject()
+	if err := tree.Encode(obj); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	return repo.Storer.SetEncodedObject(obj)
+}
+
+func notesSignature(repo *git.Repository) object.Signature {
+	sig := object.Signature{Name: "ai-detection", Email: "ai-detection@localhost", When: time.Now()}
+	cfg, err := repo.ConfigScoped(config.SystemScope)
+	if err != nil {
+		return sig
+	}
+	if cfg.User.Name != "" {
+		sig.Name = cfg.User.Name
+	}
+	if cfg.User.Email != "" {
+		sig.Email = cfg.User.Email
+	}
+	return sig
+}
@@ -0,0 +1,178 @@
+package gitops
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+func TestNotesRef(t *testing.T) {
+	cases := map[string]string{
+		"ai-detection":            "refs/notes/ai-detection",
+		"refs/notes/ai-detection": "refs/notes/ai-detection",
+		"refs/custom/notes":       "refs/custom/notes",
+	}
+	for in, want := range cases {
+		if got := NotesRef(in); got != want {
+			t.Errorf("NotesRef(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestReadNotesMissingRef(t *testing.T) {
+	dir, hashes := initTestRepo(t)
+
+	notes, err := ReadNotes(dir, "ai-detection")
+	if err != nil {
+		t.Fatalf("ReadNotes: %v", err)
+	}
+	if notes.Len() != 0 {
+		t.Errorf("Len = %d, want 0", notes.Len())
+	}
+	if _, ok, err := notes.Get(hashes[0]); ok || err != nil {
+		t.Errorf("Get = %v, %v; want no note", ok, err)
+	}
+}
+
+func TestWriteReadNotes(t *testing.T) {
+	dir, hashes := initTestRepo(t)
+
+	err := WriteNotes(dir, "ai-detection", map[string][]byte{
+		hashes[0]: []byte("first\n"),
+		hashes[1]: []byte("second\n"),
+	}, "add notes\n")
+	if err != nil {
+		t.Fatalf("WriteNotes: %v", err)
+	}
+	// A second write replaces one note and keeps the other.
+	if err := WriteNotes(dir, "ai-detection", map[string][]byte{hashes[1]: []byte("second, again\n")}, "update notes\n"); err != nil {
+		t.Fatalf("WriteNotes: %v", err)
+	}
+
+	notes, err := ReadNotes(dir, "refs/notes/ai-detection")
+	if err != nil {
+		t.Fatalf("ReadNotes: %v", err)
+	}
+	want := map[string]string{hashes[0]: "first\n", hashes[1]: "second, again\n"}
+	if notes.Len() != len(want) {
+		t.Errorf("Len = %d, want %d", notes.Len(), len(want))
+	}
+	for hash, text := range want {
+		data, ok, err := notes.Get(hash)
+		if err != nil || !ok || string(data) != text {
+			t.Errorf("Get(%s) = %q, %v, %v; want %q", hash[:7], data, ok, err, text)
+		}
+	}
+	if _, ok, _ := notes.Get(hashes[2]); ok {
+		t.Error("unexpected note on a commit that has none")
+	}
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	ref, err := repo.Reference("refs/notes/ai-detection", true)
+	if err != nil {
+		t.Fatalf("notes ref: %v", err)
+	}
+	tip := mustCommit(t, repo, ref.Hash().String())
+	if tip.NumParents() != 1 || tip.Message != "update notes\n" {
+		t.Errorf("notes tip has %d parents and message %q, want the second write on top of the first", tip.NumParents(), tip.Message)
+	}
+
+	// The notes are readable by git itself, when it is installed.
+	if _, err := exec.LookPath("git"); err != nil {
+		return
+	}
+	out, err := exec.Command("git", "-C", dir, "notes", "--ref=ai-detection", "show", hashes[1]).Output()
+	if err != nil {
+		t.Fatalf("git notes show: %v", err)
+	}
+	if string(out) != "second, again\n" {
+		t.Errorf("git notes show = %q", out)
+	}
+}
+
+func TestNotesFanout(t *testing.T) {
+	dir, _ := initTestRepo(t)
+
+	notes := map[string][]byte{}
+	for i := range notesFanout + 50 {
+		hash := plumbing.ComputeHash(plumbing.BlobObject, []byte(fmt.Sprint(i))).String()
+		notes[hash] = []byte(hash)
+	}
+	if err := WriteNotes(dir, "ai-detection", notes, "many notes\n"); err != nil {
+		t.Fatalf("WriteNotes: %v", err)
+	}
+
+	read, err := ReadNotes(dir, "ai-detection")
+	if err != nil {
+		t.Fatalf("ReadNotes: %v", err)
+	}
+	if read.Len() != len(notes) {
+		t.Fatalf("Len = %d, want %d", read.Len(), len(notes))
+	}
+	for hash := range notes {
+		data, ok, err := read.Get(hash)
+		if err != nil || !ok || string(data) != hash {
+			t.Fatalf("Get(%s) = %q, %v, %v", hash, data, ok, err)
+		}
+	}
+
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	ref, err := repo.Reference("refs/notes/ai-detection", true)
+	if err != nil {
+		t.Fatalf("notes ref: %v", err)
+	}
+	tree, err := mustCommit(t, repo, ref.Hash().String()).Tree()
+	if err != nil {
+		t.Fatalf("tree: %v", err)
+	}
+	for _, e := range tree.Entries {
+		if len(e.Name) != 2 || strings.Trim(e.Name, "0123456789abcdef") != "" {
+			t.Fatalf("top-level entry %q, want two-digit fanout directories", e.Name)
+		}
+	}
+}
+
+func TestWriteNotesInvalidHash(t *testing.T) {
+	dir, _ := initTestRepo(t)
+	if err := WriteNotes(dir, "ai-detection", map[string][]byte{"HEAD": []byte("x")}, "bad\n"); err == nil {
+		t.Error("expected error for a note keyed by a non-hash")
+	}
+}
+
+func TestCommitsAuthorshipLog(t *testing.T) {
+	dir, hashes := initTestRepo(t)
+	if err := WriteNotes(dir, "ai", map[string][]byte{hashes[1]: []byte("log\n")}, "git-ai\n"); err != nil {
+		t.Fatalf("WriteNotes: %v", err)
+	}
+
+	for c, err := range Commits(dir, "") {
+		if err != nil {
+			t.Fatalf("Commits: %v", err)
+		}
+		want := ""
+		if c.Hash == hashes[1] {
+			want = "log\n"
+		}
+		if string(c.AuthorshipLog) != want {
+			t.Errorf("commit %s: AuthorshipLog = %q, want %q", c.Hash[:7], c.AuthorshipLog, want)
+		}
+	}
+
+	c, err := GetCommit(dir, hashes[1])
+	if err != nil {
+		t.Fatalf("GetCommit: %v", err)
+	}
+	if string(c.AuthorshipLog) != "log\n" {
+		t.Errorf("GetCommit: AuthorshipLog = %q", c.AuthorshipLog)
+	}
+}
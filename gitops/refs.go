@@ -0,0 +1,192 @@
+package gitops
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+)
+
+// Ref is a reference and the commit it points to.
+type Ref struct {
+	Name   string // Full name, e.g. refs/remotes/origin/copilot/fix-login
+	Commit string
+}
+
+// BranchName returns the branch a ref name refers to, without its
+// refs/heads/ or refs/remotes/<remote>/ prefix. Other ref names are returned
+// unchanged.
+func BranchName(name string) string {
+	if branch, ok := strings.CutPrefix(name, "refs/heads/"); ok {
+		return branch
+	}
+	if rest, ok := strings.CutPrefix(name, "refs/remotes/"); ok {
+		if _, branch, ok := strings.Cut(rest, "/"); ok {
+			return branch
+		}
+	}
+	return name
+}
+
+// ListRefs returns the branches, remote-tracking branches and other refs of
+// the repository at repoPath that point directly at a commit, sorted by name.
+// Tags, notes, the stash and symbolic refs such as HEAD are left out.
+func ListRefs(repoPath string) ([]Ref, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening repo: %w", err)
+	}
+	iter, err := repo.References()
+	if err != nil {
+		return nil, fmt.Errorf("listing refs: %w", err)
+	}
+	defer iter.Close()
+
+	var refs []Ref
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		name := ref.Name()
+		if ref.Type() != plumbing.HashReference || name == plumbing.HEAD || name.IsTag() || name.IsNote() || name == "refs/stash" {
+			return nil
+		}
+		if _, err := repo.CommitObject(ref.Hash()); err != nil {
+			// Refs to trees or blobs, or to commits a shallow clone lacks.
+			return nil
+		}
+		refs = append(refs, Ref{Name: name.String(), Commit: ref.Hash().String()})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("listing refs: %w", err)
+	}
+	sort.Slice(refs, func(i, j int) bool { return refs[i].Name < refs[j].Name })
+	return refs, nil
+}
+
+// RefCommits maps each commit to the names of the refs it was made on: for
+// every ref, the commits reachable from it that are not on the first-parent
+// history of the default branch. A branch therefore keeps its commits after
+// it is merged, or squash-merged, into the default branch, but not after it
+// is fast-forwarded into it. The default branch is the one recorded in
+// refs/remotes/origin/HEAD, or else the first of main, master, origin/main
+// and origin/master that exists; with none of them nothing can be told apart
+// from it, and no commit is mapped. The default branch's history is read only
+// as far back as the refs reach.
+func RefCommits(repoPath string, refs []Ref) (map[string][]string, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("opening repo: %w", err)
+	}
+	index := openCommitIndex(repo)
+	defer index.Close()
+	return refCommits(repo, index, refs)
+}
+
+func refCommits(repo *git.Repository, index commitgraph.CommitNodeIndex, refs []Ref) (map[string][]string, error) {
+	commits := map[string][]string{}
+	main, err := newMainline(repo, index)
+	if err != nil || main == nil {
+		return commits, err
+	}
+
+	for _, ref := range refs {
+		seen := map[plumbing.Hash]bool{}
+		stack := []plumbing.Hash{plumbing.NewHash(ref.Commit)}
+		for len(stack) > 0 {
+			hash := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if seen[hash] {
+				continue
+			}
+			seen[hash] = true
+
+			node, err := index.Get(hash)
+			if errors.Is(err, plumbing.ErrObjectNotFound) {
+				continue // shallow boundary
+			}
+			if err != nil {
+				return nil, fmt.Errorf("walking %s: %w", ref.Name, err)
+			}
+			onMain, err := main.contains(node)
+			if err != nil {
+				return nil, err
+			}
+			if onMain {
+				continue
+			}
+			commits[hash.String()] = append(commits[hash.String()], ref.Name)
+			stack = append(stack, node.ParentHashes()...)
+		}
+	}
+	return commits, nil
+}
+
+// mainline is the first-parent history of the default branch, read from the
+// tip down only as far as the commits asked about need.
+type mainline struct {
+	name  plumbing.ReferenceName
+	index commitgraph.CommitNodeIndex
+	read  map[plumbing.Hash]bool
+	next  *walkEntry // the first commit not read yet, nil at the root
+}
+
+// newMainline returns the mainline of repo, or nil if it has no default
+// branch.
+func newMainline(repo *git.Repository, index commitgraph.CommitNodeIndex) (*mainline, error) {
+	name, err := defaultBranch(repo)
+	if err != nil {
+		return nil, nil
+	}
+	tip, err := repo.Reference(name, true)
+	if err != nil {
+		return nil, nil
+	}
+	m := &mainline{name: name, index: index, read: map[plumbing.Hash]bool{}}
+	if err := m.queue(tip.Hash()); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// contains reports whether node is on the mainline. Reading stops once the
+// mainline has gone past node in walk order; without generation numbers it
+// goes walkSlop commits further, as walkRange does, to allow for clock skew.
+func (m *mainline) contains(node commitgraph.CommitNode) (bool, error) {
+	e := newWalkEntry(node)
+	slop := walkSlop
+	for !m.read[node.ID()] && m.next != nil {
+		if e.before(*m.next) {
+			if m.next.gen != unknownGeneration || slop == 0 {
+				break
+			}
+			slop--
+		}
+		next := m.next
+		m.read[next.node.ID()] = true
+		m.next = nil
+		if parents := next.node.ParentHashes(); len(parents) > 0 {
+			if err := m.queue(parents[0]); err != nil {
+				return false, err
+			}
+		}
+	}
+	return m.read[node.ID()], nil
+}
+
+// queue makes hash the next mainline commit to read. A commit missing from
+// a shallow clone ends the mainline.
+func (m *mainline) queue(hash plumbing.Hash) error {
+	node, err := m.index.Get(hash)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("walking %s: %w", m.name, err)
+	}
+	e := newWalkEntry(node)
+	m.next = &e
+	return nil
+}
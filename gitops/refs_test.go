@@ -0,0 +1,205 @@
+package gitops
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// agentBranchRepo builds
+//
+//	c0 - c1 ------- m2 - c3   main
+//	       \       /     \
+//	        a1 - a2       b1  copilot/docs
+//	        claude/fix
+//
+// where claude/fix (at a2) was merged into main, and origin/claude/fix
+// tracks it. c1 carries the tag v1 and c0 a note, neither of which is a
+// ref ListRefs returns.
+func agentBranchRepo(t *testing.T) (string, map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+
+	emptyTree := repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{}).Encode(emptyTree); err != nil {
+		t.Fatalf("encode tree: %v", err)
+	}
+	treeHash, err := repo.Storer.SetEncodedObject(emptyTree)
+	if err != nil {
+		t.Fatalf("store tree: %v", err)
+	}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	hashes := map[string]string{}
+	commit := func(name string, parents ...string) plumbing.Hash {
+		sig := object.Signature{Name: "Test", Email: "test@example.com", When: base.Add(time.Duration(len(hashes)) * time.Minute)}
+		c := &object.Commit{Author: sig, Committer: sig, Message: name, TreeHash: treeHash}
+		for _, p := range parents {
+			c.ParentHashes = append(c.ParentHashes, plumbing.NewHash(hashes[p]))
+		}
+		obj := repo.Storer.NewEncodedObject()
+		if err := c.Encode(obj); err != nil {
+			t.Fatalf("encode commit: %v", err)
+		}
+		h, err := repo.Storer.SetEncodedObject(obj)
+		if err != nil {
+			t.Fatalf("store commit: %v", err)
+		}
+		hashes[name] = h.String()
+		return h
+	}
+
+	commit("c0")
+	c1 := commit("c1", "c0")
+	commit("a1", "c1")
+	a2 := commit("a2", "a1")
+	commit("m2", "c1", "a2")
+	c3 := commit("c3", "m2")
+	b1 := commit("b1", "c3")
+
+	refs := []*plumbing.Reference{
+		plumbing.NewHashReference("refs/heads/main", c3),
+		plumbing.NewHashReference("refs/heads/claude/fix", a2),
+		plumbing.NewHashReference("refs/heads/copilot/docs", b1),
+		plumbing.NewHashReference("refs/remotes/origin/claude/fix", a2),
+		plumbing.NewSymbolicReference(plumbing.HEAD, "refs/heads/main"),
+	}
+	for _, ref := range refs {
+		if err := repo.Storer.SetReference(ref); err != nil {
+			t.Fatalf("set ref: %v", err)
+		}
+	}
+	sig := &object.Signature{Name: "Test", Email: "test@example.com", When: base}
+	if _, err := repo.CreateTag("v1", c1, &git.CreateTagOptions{Tagger: sig, Message: "v1"}); err != nil {
+		t.Fatalf("tag: %v", err)
+	}
+	if err := WriteNotes(dir, "ai-detection", map[string][]byte{hashes["c0"]: []byte("note\n")}, "note\n"); err != nil {
+		t.Fatalf("WriteNotes: %v", err)
+	}
+	return dir, hashes
+}
+
+func TestBranchName(t *testing.T) {
+	cases := map[string]string{
+		"refs/heads/copilot/fix":          "copilot/fix",
+		"refs/remotes/origin/copilot/fix": "copilot/fix",
+		"refs/remotes/upstream/main":      "main",
+		"refs/entire/checkpoints":         "refs/entire/checkpoints",
+		"copilot/fix":                     "copilot/fix",
+		"refs/remotes/origin":             "refs/remotes/origin",
+	}
+	for in, want := range cases {
+		if got := BranchName(in); got != want {
+			t.Errorf("BranchName(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestListRefs(t *testing.T) {
+	dir, hashes := agentBranchRepo(t)
+
+	refs, err := ListRefs(dir)
+	if err != nil {
+		t.Fatalf("ListRefs: %v", err)
+	}
+	want := []Ref{
+		{Name: "refs/heads/claude/fix", Commit: hashes["a2"]},
+		{Name: "refs/heads/copilot/docs", Commit: hashes["b1"]},
+		{Name: "refs/heads/main", Commit: hashes["c3"]},
+		{Name: "refs/remotes/origin/claude/fix", Commit: hashes["a2"]},
+	}
+	if !slices.Equal(refs, want) {
+		t.Errorf("ListRefs = %+v\nwant %+v", refs, want)
+	}
+}
+
+func TestRefCommits(t *testing.T) {
+	dir, hashes := agentBranchRepo(t)
+	refs := []Ref{
+		{Name: "refs/heads/claude/fix", Commit: hashes["a2"]},
+		{Name: "refs/heads/copilot/docs", Commit: hashes["b1"]},
+		{Name: "refs/remotes/origin/claude/fix", Commit: hashes["a2"]},
+	}
+
+	commits, err := RefCommits(dir, refs)
+	if err != nil {
+		t.Fatalf("RefCommits: %v", err)
+	}
+	// The merged branch keeps its commits; main's own history, including
+	// the merge commit, belongs to no branch.
+	claude := []string{"refs/heads/claude/fix", "refs/remotes/origin/claude/fix"}
+	want := map[string][]string{
+		hashes["a1"]: claude,
+		hashes["a2"]: claude,
+		hashes["b1"]: {"refs/heads/copilot/docs"},
+	}
+	if len(commits) != len(want) {
+		t.Errorf("got %d commits, want %d: %v", len(commits), len(want), commits)
+	}
+	for hash, names := range want {
+		if !slices.Equal(commits[hash], names) {
+			t.Errorf("commit %s: refs %v, want %v", hash[:7], commits[hash], names)
+		}
+	}
+}
+
+func TestRefCommitsNoDefaultBranch(t *testing.T) {
+	dir, hashes := agentBranchRepo(t)
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	if err := repo.Storer.RemoveReference("refs/heads/main"); err != nil {
+		t.Fatalf("remove main: %v", err)
+	}
+
+	// Without a default branch nothing is mapped, rather than the whole
+	// history reachable from the ref.
+	commits, err := RefCommits(dir, []Ref{{Name: "refs/heads/claude/fix", Commit: hashes["a2"]}})
+	if err != nil {
+		t.Fatalf("RefCommits: %v", err)
+	}
+	if len(commits) != 0 {
+		t.Errorf("got %d commits, want none: %v", len(commits), commits)
+	}
+}
+
+func TestRefCommitsReadsMainlineNearRefs(t *testing.T) {
+	dir, main := forkRepo(t, 500)
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	feature, err := repo.Reference("refs/heads/feature", true)
+	if err != nil {
+		t.Fatalf("feature: %v", err)
+	}
+
+	for _, graph := range []bool{false, true} {
+		if graph {
+			writeCommitGraph(t, dir)
+		}
+		index := openCommitIndex(repo)
+		counter := &countingIndex{CommitNodeIndex: index.CommitNodeIndex}
+		commits, err := refCommits(repo, counter, []Ref{{Name: "refs/heads/feature", Commit: feature.Hash().String()}})
+		index.Close()
+		if err != nil {
+			t.Fatalf("graph=%v: refCommits: %v", graph, err)
+		}
+		if len(commits) != 2 || commits[main[len(main)-3].String()] != nil {
+			t.Errorf("graph=%v: got %v, want the two feature commits", graph, commits)
+		}
+		if counter.reads > 20 {
+			t.Errorf("graph=%v: read %d commits for a two-commit branch", graph, counter.reads)
+		}
+	}
+}
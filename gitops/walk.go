@@ -0,0 +1,312 @@
+package gitops
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"iter"
+	"math"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	commitgraphfmt "github.com/go-git/go-git/v5/plumbing/format/commitgraph/v2"
+	"github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+)
+
+// commitIndex reads the commit nodes history walks step through. When the
+// repository has a commit-graph file (written by git commit-graph write, or
+// by git gc and git fetch with fetch.writeCommitGraph), nodes come from it
+// with their generation numbers, and the commit objects are only read for
+// the commits a walk returns.
+type commitIndex struct {
+	commitgraph.CommitNodeIndex
+	graph commitgraphfmt.Index
+}
+
+// openCommitIndex returns the commit index for repo. A missing or unreadable
+// commit-graph is not an error; nodes are then read from the object store.
+func openCommitIndex(repo *git.Repository) *commitIndex {
+	if fs, ok := repo.Storer.(*filesystem.Storage); ok {
+		if graph, err := commitgraphfmt.OpenChainOrFileIndex(fs.Filesystem()); err == nil {
+			return &commitIndex{
+				CommitNodeIndex: commitgraph.NewGraphCommitNodeIndex(graph, repo.Storer),
+				graph:           graph,
+			}
+		}
+	}
+	return &commitIndex{CommitNodeIndex: commitgraph.NewObjectCommitNodeIndex(repo.Storer)}
+}
+
+// Close releases the commit-graph file, if one was opened.
+func (ci *commitIndex) Close() {
+	if ci.graph != nil {
+		ci.graph.Close()
+	}
+}
+
+// Flags painted onto the commits a walker visits.
+const (
+	flagUninteresting uint8 = 1 << iota // reachable from an excluded commit
+	flagParent1                         // reachable from the first side of a merge base
+	flagParent2                         // reachable from the second side
+	flagStale                           // reachable from a merge base already found
+)
+
+// unknownGeneration is the generation of a commit outside the commit-graph.
+// Such commits can only be descendants of the commits in it, so they sort
+// first.
+const unknownGeneration = math.MaxUint64
+
+// walkEntry is a queued commit and its sort key.
+type walkEntry struct {
+	node commitgraph.CommitNode
+	gen  uint64
+	when time.Time
+}
+
+func newWalkEntry(node commitgraph.CommitNode) walkEntry {
+	gen := node.Generation()
+	if gen == 0 {
+		// Graphs written by old versions of git leave generations zero.
+		gen = unknownGeneration
+	}
+	return walkEntry{node: node, gen: gen, when: node.CommitTime()}
+}
+
+// before reports whether e is popped before other: higher generation first,
+// then newer committer time. Without a commit-graph every generation is
+// unknown and this is the committer-time order git log uses.
+func (e walkEntry) before(other walkEntry) bool {
+	if e.gen != other.gen {
+		return e.gen > other.gen
+	}
+	return e.when.After(other.when)
+}
+
+// settledBy reports whether no commit popped after next can be a descendant
+// of e, so the flags on e are final. Only generation numbers prove it:
+// committer times can run backwards when a clock is wrong, so without them a
+// commit is not settled until the walk ends.
+func (e walkEntry) settledBy(next walkEntry) bool {
+	return e.gen != unknownGeneration || next.gen != unknownGeneration
+}
+
+// walkSlop is how many commits a walk ordered by committer time goes on for
+// once every queued commit is uninteresting, in case an older-looking commit
+// still leads back into the range. git's revision walk uses the same margin.
+const walkSlop = 5
+
+type walkQueue []walkEntry
+
+func (q walkQueue) Len() int           { return len(q) }
+func (q walkQueue) Less(i, j int) bool { return q[i].before(q[j]) }
+func (q walkQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *walkQueue) Push(x any)        { *q = append(*q, x.(walkEntry)) }
+func (q *walkQueue) Pop() any {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return e
+}
+
+// walkState is what a walker knows about a commit it has reached.
+type walkState struct {
+	flags   uint8
+	queued  bool
+	parents []plumbing.Hash // set once the commit is popped
+}
+
+// walker paints flags down the history from its starting commits, popping
+// commits in walkEntry order. Callers stop once active is zero: every commit
+// left in the queue then carries the done flag, and so does everything
+// reachable from them.
+type walker struct {
+	index  commitgraph.CommitNodeIndex
+	done   uint8
+	queue  walkQueue
+	state  map[plumbing.Hash]*walkState
+	active int // queued commits without the done flag
+}
+
+func newWalker(index commitgraph.CommitNodeIndex, done uint8) *walker {
+	return &walker{index: index, done: done, state: map[plumbing.Hash]*walkState{}}
+}
+
+// paint adds flags to the commit hash, queueing it the first time it is
+// reached. Flags that reach a commit which was already popped are carried on
+// to its ancestors. A commit missing from the object store marks the edge of
+// a shallow clone and is treated as the end of history.
+func (w *walker) paint(hash plumbing.Hash, flags uint8) error {
+	stack := []plumbing.Hash{hash}
+	for len(stack) > 0 {
+		h := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		st, ok := w.state[h]
+		if !ok {
+			node, err := w.index.Get(h)
+			if errors.Is(err, plumbing.ErrObjectNotFound) {
+				w.state[h] = &walkState{flags: flags}
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("reading commit %s: %w", h, err)
+			}
+			w.state[h] = &walkState{flags: flags, queued: true}
+			heap.Push(&w.queue, newWalkEntry(node))
+			if flags&w.done == 0 {
+				w.active++
+			}
+			continue
+		}
+
+		added := flags &^ st.flags
+		if added == 0 {
+			continue
+		}
+		if st.queued && st.flags&w.done == 0 && added&w.done != 0 {
+			w.active--
+		}
+		st.flags |= added
+		if !st.queued {
+			stack = append(stack, st.parents...)
+		}
+	}
+	return nil
+}
+
+// pop removes the next commit from the queue and paints its flags onto its
+// parents.
+func (w *walker) pop() (walkEntry, *walkState, error) {
+	e := heap.Pop(&w.queue).(walkEntry)
+	st := w.state[e.node.ID()]
+	st.queued = false
+	st.parents = e.node.ParentHashes()
+	if st.flags&w.done == 0 {
+		w.active--
+	}
+	for _, p := range st.parents {
+		if err := w.paint(p, st.flags); err != nil {
+			return walkEntry{}, nil, err
+		}
+	}
+	return e, st, nil
+}
+
+// walkRange yields the commits in r, newest first. Excluded and included
+// commits are walked together, and the walk ends as soon as every queued
+// commit is reachable from an excluded one, so scanning a short branch reads
+// only the history near its fork point rather than all of the base. Without
+// generation numbers the walk goes walkSlop commits further to allow for
+// clock skew, and commits are yielded once it ends.
+func walkRange(r revisionRange) iter.Seq2[commitgraph.CommitNode, error] {
+	return func(yield func(commitgraph.CommitNode, error) bool) {
+		w := newWalker(r.index, flagUninteresting)
+		for _, h := range r.exclude {
+			if err := w.paint(h, flagUninteresting); err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+		for _, h := range r.include {
+			if err := w.paint(h, 0); err != nil {
+				yield(nil, err)
+				return
+			}
+		}
+
+		// pending holds popped commits, in order, that a commit still queued
+		// could yet mark uninteresting.
+		var pending []walkEntry
+		flush := func(next *walkEntry) bool {
+			for len(pending) > 0 && (next == nil || len(r.exclude) == 0 || pending[0].settledBy(*next)) {
+				e := pending[0]
+				pending = pending[1:]
+				if w.state[e.node.ID()].flags&flagUninteresting != 0 {
+					continue
+				}
+				if !yield(e.node, nil) {
+					return false
+				}
+			}
+			return true
+		}
+
+		slop := walkSlop
+		for w.queue.Len() > 0 {
+			switch {
+			case w.active > 0:
+				slop = walkSlop
+			case w.queue[0].gen != unknownGeneration:
+				// Generation numbers order the rest of the walk exactly;
+				// it only needs to settle the pending commits.
+				if len(pending) == 0 {
+					return
+				}
+			default:
+				if slop == 0 {
+					flush(nil)
+					return
+				}
+				slop--
+			}
+
+			e, st, err := w.pop()
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !flush(&e) {
+				return
+			}
+			if st.flags&flagUninteresting == 0 {
+				pending = append(pending, e)
+			}
+		}
+		flush(nil)
+	}
+}
+
+// mergeBases returns the best common ancestors of a and b, as git merge-base
+// --all does: the common ancestors not reachable from another one. The walk
+// stops once every queued commit is reachable from a base already found.
+func mergeBases(index commitgraph.CommitNodeIndex, a, b plumbing.Hash) ([]plumbing.Hash, error) {
+	if a == b {
+		return []plumbing.Hash{a}, nil
+	}
+
+	w := newWalker(index, flagStale)
+	if err := w.paint(a, flagParent1); err != nil {
+		return nil, err
+	}
+	if err := w.paint(b, flagParent2); err != nil {
+		return nil, err
+	}
+
+	var found []plumbing.Hash
+	for w.active > 0 {
+		e, st, err := w.pop()
+		if err != nil {
+			return nil, err
+		}
+		if st.flags&(flagParent1|flagParent2|flagStale) == flagParent1|flagParent2 {
+			found = append(found, e.node.ID())
+			for _, p := range st.parents {
+				if err := w.paint(p, flagStale); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	// A base painted stale after it was found is an ancestor of another.
+	var bases []plumbing.Hash
+	for _, h := range found {
+		if w.state[h].flags&flagStale == 0 {
+			bases = append(bases, h)
+		}
+	}
+	return bases, nil
+}
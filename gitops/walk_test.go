@@ -0,0 +1,369 @@
+package gitops
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	commitgraphfmt "github.com/go-git/go-git/v5/plumbing/format/commitgraph/v2"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/object/commitgraph"
+)
+
+// writeCommitGraph writes objects/info/commit-graph for every commit in the
+// repository at dir, as git commit-graph write would.
+func writeCommitGraph(tb testing.TB, dir string) {
+	tb.Helper()
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		tb.Fatalf("open: %v", err)
+	}
+
+	commits := map[plumbing.Hash]*object.Commit{}
+	iter, err := repo.CommitObjects()
+	if err != nil {
+		tb.Fatalf("commit objects: %v", err)
+	}
+	if err := iter.ForEach(func(c *object.Commit) error {
+		commits[c.Hash] = c
+		return nil
+	}); err != nil {
+		tb.Fatalf("iterate commits: %v", err)
+	}
+
+	// A commit's generation is one more than its highest parent's.
+	generations := map[plumbing.Hash]uint64{}
+	var generation func(h plumbing.Hash) uint64
+	generation = func(h plumbing.Hash) uint64 {
+		if g, ok := generations[h]; ok {
+			return g
+		}
+		var g uint64
+		for _, p := range commits[h].ParentHashes {
+			g = max(g, generation(p))
+		}
+		generations[h] = g + 1
+		return g + 1
+	}
+
+	index := commitgraphfmt.NewMemoryIndex()
+	for h, c := range commits {
+		index.Add(h, &commitgraphfmt.CommitData{
+			TreeHash:     c.TreeHash,
+			ParentHashes: c.ParentHashes,
+			Generation:   generation(h),
+			When:         c.Committer.When,
+		})
+	}
+
+	path := filepath.Join(dir, ".git", "objects", "info", "commit-graph")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		tb.Fatalf("mkdir: %v", err)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		tb.Fatalf("create commit-graph: %v", err)
+	}
+	defer f.Close()
+	if err := commitgraphfmt.NewEncoder(f).Encode(index); err != nil {
+		tb.Fatalf("encode commit-graph: %v", err)
+	}
+}
+
+// forkRepo builds a main branch of length commits and a feature branch of
+// two commits forked from main three commits before its tip, with HEAD on
+// feature. It returns the repository path and the main commits, oldest
+// first.
+func forkRepo(tb testing.TB, length int) (string, []plumbing.Hash) {
+	tb.Helper()
+	dir := tb.TempDir()
+
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		tb.Fatalf("init repo: %v", err)
+	}
+
+	emptyTree := repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{}).Encode(emptyTree); err != nil {
+		tb.Fatalf("encode tree: %v", err)
+	}
+	treeHash, err := repo.Storer.SetEncodedObject(emptyTree)
+	if err != nil {
+		tb.Fatalf("store tree: %v", err)
+	}
+
+	base := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	minute := 0
+	commit := func(parents ...plumbing.Hash) plumbing.Hash {
+		minute++
+		sig := object.Signature{Name: "Test", Email: "test@example.com", When: base.Add(time.Duration(minute) * time.Minute)}
+		c := &object.Commit{Author: sig, Committer: sig, Message: "commit", TreeHash: treeHash, ParentHashes: parents}
+		obj := repo.Storer.NewEncodedObject()
+		if err := c.Encode(obj); err != nil {
+			tb.Fatalf("encode commit: %v", err)
+		}
+		h, err := repo.Storer.SetEncodedObject(obj)
+		if err != nil {
+			tb.Fatalf("store commit: %v", err)
+		}
+		return h
+	}
+
+	main := []plumbing.Hash{commit()}
+	for len(main) < length-2 {
+		main = append(main, commit(main[len(main)-1]))
+	}
+	feature := commit(main[len(main)-1])
+	feature = commit(feature)
+	main = append(main, commit(main[len(main)-1]))
+	main = append(main, commit(main[len(main)-1]))
+
+	refs := []*plumbing.Reference{
+		plumbing.NewHashReference("refs/heads/main", main[len(main)-1]),
+		plumbing.NewHashReference("refs/heads/feature", feature),
+		plumbing.NewSymbolicReference(plumbing.HEAD, "refs/heads/feature"),
+	}
+	for _, ref := range refs {
+		if err := repo.Storer.SetReference(ref); err != nil {
+			tb.Fatalf("set ref: %v", err)
+		}
+	}
+	return dir, main
+}
+
+// countingIndex counts the commit nodes a walk reads.
+type countingIndex struct {
+	commitgraph.CommitNodeIndex
+	reads int
+}
+
+func (ci *countingIndex) Get(h plumbing.Hash) (commitgraph.CommitNode, error) {
+	ci.reads++
+	return ci.CommitNodeIndex.Get(h)
+}
+
+func TestListCommitsCommitGraph(t *testing.T) {
+	dir, hashes := historyRepo(t)
+	writeCommitGraph(t, dir)
+	checkRevisionSyntax(t, dir, hashes)
+
+	// Commits made after the graph was written are read from objects.
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	c3 := mustCommit(t, repo, hashes["c3"])
+	sig := object.Signature{Name: "Test", Email: "test@example.com", When: c3.Committer.When.Add(time.Minute)}
+	c4 := &object.Commit{Author: sig, Committer: sig, Message: "c4", TreeHash: c3.TreeHash, ParentHashes: []plumbing.Hash{c3.Hash}}
+	obj := repo.Storer.NewEncodedObject()
+	if err := c4.Encode(obj); err != nil {
+		t.Fatalf("encode: %v", err)
+	}
+	h, err := repo.Storer.SetEncodedObject(obj)
+	if err != nil {
+		t.Fatalf("store: %v", err)
+	}
+
+	commits, err := ListCommits(dir, "feature.."+h.String())
+	if err != nil {
+		t.Fatalf("ListCommits: %v", err)
+	}
+	want := []string{h.String(), hashes["c3"], hashes["c2"]}
+	if len(commits) != len(want) {
+		t.Fatalf("got %d commits, want %d", len(commits), len(want))
+	}
+	for i, c := range commits {
+		if c.Hash != want[i] {
+			t.Errorf("commit %d = %s, want %s", i, c.Hash, want[i])
+		}
+	}
+}
+
+func TestWalkRangeStopsNearFork(t *testing.T) {
+	dir, main := forkRepo(t, 500)
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+
+	for _, graph := range []bool{false, true} {
+		if graph {
+			writeCommitGraph(t, dir)
+		}
+		index := openCommitIndex(repo)
+		if graph && index.graph == nil {
+			t.Fatal("commit-graph not opened")
+		}
+		counter := &countingIndex{CommitNodeIndex: index.CommitNodeIndex}
+
+		r, err := parseRange(repo, &commitIndex{CommitNodeIndex: counter}, "main..feature")
+		if err != nil {
+			t.Fatalf("parseRange: %v", err)
+		}
+		n := 0
+		for _, err := range walkRange(r) {
+			if err != nil {
+				t.Fatalf("walk: %v", err)
+			}
+			n++
+		}
+		if n != 2 {
+			t.Errorf("graph=%v: walked %d commits, want 2", graph, n)
+		}
+		if counter.reads > 10 {
+			t.Errorf("graph=%v: read %d commits for a two-commit branch", graph, counter.reads)
+		}
+
+		counter.reads = 0
+		bases, err := mergeBases(counter, main[len(main)-1], r.include[0])
+		if err != nil {
+			t.Fatalf("mergeBases: %v", err)
+		}
+		if len(bases) != 1 || bases[0] != main[len(main)-3] {
+			t.Errorf("graph=%v: merge bases = %v, want %s", graph, bases, main[len(main)-3])
+		}
+		if counter.reads > 10 {
+			t.Errorf("graph=%v: read %d commits to find the merge base", graph, counter.reads)
+		}
+		index.Close()
+	}
+}
+
+// TestWalkRangeClockSkew walks a range whose excluded side was committed
+// with a clock running behind, so it sorts after the base commit it shares
+// with the included side.
+func TestWalkRangeClockSkew(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	emptyTree := repo.Storer.NewEncodedObject()
+	if err := (&object.Tree{}).Encode(emptyTree); err != nil {
+		t.Fatalf("encode tree: %v", err)
+	}
+	treeHash, err := repo.Storer.SetEncodedObject(emptyTree)
+	if err != nil {
+		t.Fatalf("store tree: %v", err)
+	}
+
+	base := time.Date(2010, 1, 1, 0, 0, 0, 0, time.UTC)
+	commit := func(when time.Duration, parents ...plumbing.Hash) plumbing.Hash {
+		sig := object.Signature{Name: "Test", Email: "test@example.com", When: base.Add(when)}
+		c := &object.Commit{Author: sig, Committer: sig, Message: "commit", TreeHash: treeHash, ParentHashes: parents}
+		obj := repo.Storer.NewEncodedObject()
+		if err := c.Encode(obj); err != nil {
+			t.Fatalf("encode commit: %v", err)
+		}
+		h, err := repo.Storer.SetEncodedObject(obj)
+		if err != nil {
+			t.Fatalf("store commit: %v", err)
+		}
+		return h
+	}
+
+	root := commit(0)
+	shared := commit(time.Hour, root)
+	skewed := commit(-time.Hour, shared) // committed with a clock two hours behind
+	feature := commit(2*time.Hour, shared)
+
+	r := revisionRange{
+		index:   openCommitIndex(repo),
+		include: []plumbing.Hash{feature},
+		exclude: []plumbing.Hash{skewed},
+	}
+	defer r.index.Close()
+	var got []plumbing.Hash
+	for node, err := range walkRange(r) {
+		if err != nil {
+			t.Fatalf("walk: %v", err)
+		}
+		got = append(got, node.ID())
+	}
+	if len(got) != 1 || got[0] != feature {
+		t.Errorf("walked %v, want only %s", got, feature)
+	}
+}
+
+func TestMergeBases(t *testing.T) {
+	dir, hashes := historyRepo(t)
+	repo, err := git.PlainOpen(dir)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	index := openCommitIndex(repo)
+	defer index.Close()
+
+	cases := []struct{ a, b, want string }{
+		{"c3", "f2", "c1"},
+		{"f2", "c3", "c1"},
+		{"c3", "c1", "c1"},
+		{"c2", "c2", "c2"},
+	}
+	for _, tc := range cases {
+		bases, err := mergeBases(index, plumbing.NewHash(hashes[tc.a]), plumbing.NewHash(hashes[tc.b]))
+		if err != nil {
+			t.Fatalf("mergeBases(%s, %s): %v", tc.a, tc.b, err)
+		}
+		if len(bases) != 1 || bases[0].String() != hashes[tc.want] {
+			t.Errorf("mergeBases(%s, %s) = %v, want %s", tc.a, tc.b, bases, tc.want)
+		}
+	}
+}
+
+// The range benchmarks scan a two-commit branch forked near the tip of a long
+// main branch, the common case for pull requests, with and without a
+// commit-graph file.
+func BenchmarkListCommitsBranch(b *testing.B) {
+	dir, _ := forkRepo(b, 20000)
+	benchmarkWithCommitGraph(b, dir, func(b *testing.B) {
+		commits, err := ListCommits(dir, "main..feature")
+		if err != nil || len(commits) != 2 {
+			b.Fatalf("ListCommits = %d commits, %v", len(commits), err)
+		}
+	})
+}
+
+func BenchmarkBaseRange(b *testing.B) {
+	dir, _ := forkRepo(b, 20000)
+	benchmarkWithCommitGraph(b, dir, func(b *testing.B) {
+		if _, err := BaseRange(dir, "main"); err != nil {
+			b.Fatalf("BaseRange: %v", err)
+		}
+	})
+}
+
+func BenchmarkListCommitsAll(b *testing.B) {
+	dir, _ := forkRepo(b, 20000)
+	benchmarkWithCommitGraph(b, dir, func(b *testing.B) {
+		n := 0
+		for _, err := range Commits(dir, "main") {
+			if err != nil {
+				b.Fatalf("Commits: %v", err)
+			}
+			n++
+		}
+		if n != 20000 {
+			b.Fatalf("walked %d commits, want 20000", n)
+		}
+	})
+}
+
+// benchmarkWithCommitGraph runs op as an "objects" sub-benchmark, then writes
+// a commit-graph for dir and runs it again as "commit-graph".
+func benchmarkWithCommitGraph(b *testing.B, dir string, op func(b *testing.B)) {
+	b.Run("objects", func(b *testing.B) {
+		for range b.N {
+			op(b)
+		}
+	})
+	writeCommitGraph(b, dir)
+	b.Run("commit-graph", func(b *testing.B) {
+		for range b.N {
+			op(b)
+		}
+	})
+}
@@ -0,0 +1,44 @@
+package glob
+
+import "testing"
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"CLAUDE.md", "CLAUDE.md", true},
+		{"CLAUDE.md", "docs/CLAUDE.md", false},
+		{"**/CLAUDE.md", "CLAUDE.md", true},
+		{"**/CLAUDE.md", "pkg/api/CLAUDE.md", true},
+		{".cursor/rules/*", ".cursor/rules/go.mdc", true},
+		{".cursor/rules/*", ".cursor/rules/sub/go.mdc", false},
+		{".cursor/rules/**", ".cursor/rules/sub/go.mdc", true},
+		{"vendor/**", "vendor", true},
+		{"**/*.go", "cmd/cmd.go", true},
+		{"**/*.go", "README.md", false},
+		{"src/**/test/*.js", "src/a/b/test/x.js", true},
+		{"src/**/test/*.js", "src/test/x.js", true},
+		{"[", "[", false},
+	}
+
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	patterns := []string{"*.md", "docs/**"}
+	if !MatchAny(patterns, "docs/a/b.txt") {
+		t.Error("expected docs/a/b.txt to match")
+	}
+	if MatchAny(patterns, "src/main.go") {
+		t.Error("expected src/main.go not to match")
+	}
+	if MatchAny(nil, "anything") {
+		t.Error("expected no match with no patterns")
+	}
+}
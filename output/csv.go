@@ -0,0 +1,166 @@
+package output
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/scan"
+)
+
+// CSVRows selects what each row of CSV or TSV output stands for.
+type CSVRows int
+
+const (
+	RowsFindings CSVRows = iota // One row per finding
+	RowsCommits                 // One row per commit, with its findings aggregated
+)
+
+// ParseCSVRows parses "findings" or "commits".
+func ParseCSVRows(s string) (CSVRows, error) {
+	switch s {
+	case "findings":
+		return RowsFindings, nil
+	case "commits":
+		return RowsCommits, nil
+	default:
+		return 0, fmt.Errorf("unknown rows: %s (want findings or commits)", s)
+	}
+}
+
+// commitColumns start every row, so both kinds of rows join on commit.
+var commitColumns = []string{
+	"commit", "subject",
+	"author_name", "author_email", "author_date",
+	"committer_name", "committer_email", "committer_date",
+}
+
+var findingColumns = append(slices.Clone(commitColumns),
+	"detector", "tool", "tool_id", "confidence", "model", "ai", "recorded", "detail",
+)
+
+var aggregateColumns = append(slices.Clone(commitColumns),
+	"ai", "findings", "max_confidence", "tools", "tool_ids", "negative_disclosure",
+)
+
+// csvWriter writes rows as each commit arrives; CSV has no place for the
+// summary or metadata.
+type csvWriter struct {
+	w    *csv.Writer
+	rows CSVRows
+}
+
+// NewCSVWriter returns a ReportWriter that writes delimited rows with a
+// header line: ',' as the delimiter gives CSV and '\t' TSV. Dates are RFC
+// 3339, booleans "true" or "false", and lists in a cell are separated by
+// semicolons.
+//
+// With RowsFindings, commits without findings have no rows, and a commit's
+// recorded findings have "recorded" set. With RowsCommits, every scanned
+// commit has a row, and its recorded findings are aggregated with the rest,
+// as in the summary.
+func NewCSVWriter(w io.Writer, comma rune, rows CSVRows) ReportWriter {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if rows == RowsCommits {
+		cw.Write(aggregateColumns)
+	} else {
+		cw.Write(findingColumns)
+	}
+	return &csvWriter{w: cw, rows: rows}
+}
+
+// FormatCSV writes the report as NewCSVWriter does.
+func FormatCSV(w io.Writer, report scan.Report, comma rune, rows CSVRows) error {
+	cw := NewCSVWriter(w, comma, rows)
+	for _, cr := range report.Commits {
+		if err := cw.WriteCommit(cr); err != nil {
+			return err
+		}
+	}
+	return cw.Close(report.Summary, report.Metadata)
+}
+
+func (c *csvWriter) WriteCommit(cr scan.CommitResult) error {
+	commit := commitCells(cr)
+	if c.rows == RowsCommits {
+		c.w.Write(append(commit, aggregateCells(cr)...))
+	} else {
+		for _, findings := range []struct {
+			list     []detection.Finding
+			recorded bool
+		}{{cr.Findings, false}, {cr.Recorded, true}} {
+			for _, f := range findings.list {
+				c.w.Write(append(slices.Clone(commit), findingCells(f, findings.recorded)...))
+			}
+		}
+	}
+	// Flush per commit so output keeps up with the scan.
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close(scan.Summary, *scan.Metadata) error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func commitCells(cr scan.CommitResult) []string {
+	cells := []string{cr.Hash, cr.Subject}
+	for _, s := range []*scan.Signature{cr.Author, cr.Committer} {
+		if s == nil {
+			// Signatures are optional, e.g. in reports built by hand.
+			cells = append(cells, "", "", "")
+			continue
+		}
+		cells = append(cells, s.Name, s.Email, s.Date)
+	}
+	return cells
+}
+
+func findingCells(f detection.Finding, recorded bool) []string {
+	return []string{
+		f.Detector, f.Tool, f.ToolID, f.Confidence.String(), f.Model,
+		strconv.FormatBool(f.IndicatesAI()), strconv.FormatBool(recorded), f.Detail,
+	}
+}
+
+// aggregateCells summarizes the findings of cr: whether any indicates AI,
+// how many there are, the highest confidence among them and the tools they
+// name, in order of first appearance.
+func aggregateCells(cr scan.CommitResult) []string {
+	var (
+		n, negative    int
+		highest        detection.Confidence
+		tools, toolIDs []string
+	)
+	for _, findings := range [][]detection.Finding{cr.Findings, cr.Recorded} {
+		for _, f := range findings {
+			if !f.IndicatesAI() {
+				negative++
+				continue
+			}
+			n++
+			highest = max(highest, f.Confidence)
+			if !slices.Contains(tools, f.Tool) {
+				tools = append(tools, f.Tool)
+			}
+			if f.ToolID != "" && !slices.Contains(toolIDs, f.ToolID) {
+				toolIDs = append(toolIDs, f.ToolID)
+			}
+		}
+	}
+
+	maxCell := ""
+	if n > 0 {
+		maxCell = highest.String()
+	}
+	return []string{
+		strconv.FormatBool(n > 0), strconv.Itoa(n), maxCell,
+		strings.Join(tools, ";"), strings.Join(toolIDs, ";"), strconv.FormatBool(negative > 0),
+	}
+}
@@ -0,0 +1,88 @@
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	"github.com/chaoss/ai-detection-action/scan"
+)
+
+// csvReport is sarifReport with signatures on the commits.
+func csvReport() scan.Report {
+	report := sarifReport()
+	for i := range report.Commits {
+		report.Commits[i].Subject = "change " + report.Commits[i].Hash[:3]
+		report.Commits[i].Author = &scan.Signature{Name: "Ada", Email: "ada@example.com", Date: "2024-05-01T09:30:00+02:00"}
+		report.Commits[i].Committer = &scan.Signature{Name: "GitHub", Email: "noreply@github.com", Date: "2024-05-02T10:00:00Z"}
+	}
+	return report
+}
+
+func TestFormatCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatCSV(&buf, csvReport(), ',', RowsFindings); err != nil {
+		t.Fatalf("FormatCSV: %v", err)
+	}
+	checkGolden(t, "findings.csv", buf.Bytes())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read CSV: %v", err)
+	}
+	// A header, then the five findings; the commit without findings has no
+	// row.
+	if len(records) != 6 {
+		t.Fatalf("got %d records, want 6", len(records))
+	}
+	for _, r := range records {
+		if len(r) != len(findingColumns) {
+			t.Errorf("record has %d fields, want %d: %q", len(r), len(findingColumns), r)
+		}
+	}
+	recorded := records[4]
+	if recorded[8] != "vendor-check" || recorded[14] != "true" {
+		t.Errorf("recorded finding row = %q", recorded)
+	}
+}
+
+func TestFormatCSVCommits(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatCSV(&buf, csvReport(), '\t', RowsCommits); err != nil {
+		t.Fatalf("FormatCSV: %v", err)
+	}
+	checkGolden(t, "commits.tsv", buf.Bytes())
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("got %d lines, want a header and 4 commits:\n%s", len(lines), buf.String())
+	}
+	cursor := strings.Split(lines[3], "\t")
+	want := []string{"true", "3", "high", "Cursor;Aider", "cursor", "false"}
+	if got := cursor[len(commitColumns):]; strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("aggregate columns = %q, want %q", got, want)
+	}
+}
+
+func TestCSVWriterNoSignatures(t *testing.T) {
+	var buf bytes.Buffer
+	writeReport(t, NewCSVWriter(&buf, ',', RowsCommits), sampleReport())
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("read CSV: %v", err)
+	}
+	if len(records) != 3 || records[1][0] != "abc123def456" || records[1][2] != "" {
+		t.Errorf("records = %q", records)
+	}
+}
+
+func TestParseCSVRows(t *testing.T) {
+	if rows, err := ParseCSVRows("commits"); err != nil || rows != RowsCommits {
+		t.Errorf("ParseCSVRows(commits) = %v, %v", rows, err)
+	}
+	if _, err := ParseCSVRows("files"); err == nil {
+		t.Error("expected error for unknown rows")
+	}
+}
@@ -0,0 +1,202 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/scan"
+)
+
+// Size limits of the Markdown output, in bytes. GitHub rejects comments over
+// 65,536 characters; a scan report and a text section together stay below
+// that, so the action can post both in one comment or job summary.
+const (
+	MarkdownLimit     = 60000
+	MarkdownTextLimit = 5000
+)
+
+// FormatMarkdown writes the report as Markdown for a pull request comment or
+// a job summary: a table of tools by confidence, then the commits with
+// findings in a collapsed section. Commits that would take the document over
+// MarkdownLimit are left out, and a note says how many.
+func FormatMarkdown(w io.Writer, report scan.Report) error {
+	return formatMarkdown(w, report, MarkdownLimit)
+}
+
+func formatMarkdown(w io.Writer, report scan.Report, limit int) error {
+	var head bytes.Buffer
+	summary := report.Summary
+	fmt.Fprintf(&head, "## AI detection\n\nScanned %d commit(s), %d with AI signals.\n", summary.TotalCommits, summary.AICommits)
+
+	if summary.AICommits > 0 {
+		writeMarkdownTools(&head, report)
+	}
+	if n := summary.NegativeDisclosures; n > 0 {
+		fmt.Fprintf(&head, "\n%d commit(s) explicitly disclose no AI assistance.\n", n)
+	}
+
+	var shown []string
+	for _, cr := range report.Commits {
+		if len(cr.Findings) > 0 || len(cr.Recorded) > 0 {
+			shown = append(shown, markdownCommit(cr))
+		}
+	}
+	if len(shown) > 0 {
+		writeMarkdownDetails(&head, fmt.Sprintf("Commits with findings (%d)", len(shown)), shown, "commit(s)", limit-head.Len())
+	}
+
+	_, err := w.Write(head.Bytes())
+	return err
+}
+
+// writeMarkdownTools writes the table of tools, with how many of each tool's
+// findings are of each confidence.
+func writeMarkdownTools(w io.Writer, report scan.Report) {
+	byConfidence := map[string]map[detection.Confidence]int{}
+	for _, cr := range report.Commits {
+		for _, findings := range [][]detection.Finding{cr.Findings, cr.Recorded} {
+			for _, f := range findings {
+				if !f.IndicatesAI() {
+					continue
+				}
+				if byConfidence[f.Tool] == nil {
+					byConfidence[f.Tool] = map[detection.Confidence]int{}
+				}
+				byConfidence[f.Tool][f.Confidence]++
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "\n| Tool | Findings | High | Medium | Low |\n|---|--:|--:|--:|--:|\n")
+	for _, tool := range sortedKeys(report.Summary.ToolCounts) {
+		counts := byConfidence[tool]
+		fmt.Fprintf(w, "| %s | %d | %d | %d | %d |\n", markdownEscape(tool), report.Summary.ToolCounts[tool],
+			counts[detection.ConfidenceHigh], counts[detection.ConfidenceMedium], counts[detection.ConfidenceLow])
+	}
+}
+
+// writeMarkdownDetails writes entries in a collapsed section, leaving out
+// those that do not fit in limit bytes along with a note counting them.
+func writeMarkdownDetails(w *bytes.Buffer, title string, entries []string, unit string, limit int) {
+	open := fmt.Sprintf("\n<details>\n<summary>%s</summary>\n\n", title)
+	const closing = "\n</details>\n"
+	// Room for the note about entries left out.
+	const reserve = 120
+
+	budget := limit - len(open) - len(closing) - reserve
+	n := 0
+	for _, e := range entries {
+		if budget < len(e) {
+			break
+		}
+		budget -= len(e)
+		n++
+	}
+	if n == 0 {
+		fmt.Fprintf(w, "\n_%d %s with findings not shown, as they do not fit in the size limit._\n", len(entries), unit)
+		return
+	}
+
+	w.WriteString(open)
+	for _, e := range entries[:n] {
+		w.WriteString(e)
+	}
+	if left := len(entries) - n; left > 0 {
+		fmt.Fprintf(w, "\n_%d more %s not shown, to keep within the size limit._\n", left, unit)
+	}
+	w.WriteString(closing)
+}
+
+// markdownCommit renders a commit and its findings as a list item.
+func markdownCommit(cr scan.CommitResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "- `%s`", shortHash(cr.Hash))
+	if cr.Subject != "" {
+		fmt.Fprintf(&b, " %s", markdownEscape(cr.Subject))
+	}
+	b.WriteString("\n")
+	for _, f := range cr.Findings {
+		b.WriteString("  " + markdownFinding(f, f.Detector))
+	}
+	for _, f := range cr.Recorded {
+		b.WriteString("  " + markdownFinding(f, f.Detector+", recorded in notes"))
+	}
+	return b.String()
+}
+
+// markdownFinding renders f as a list item, crediting source like
+// writeFindingFrom does.
+func markdownFinding(f detection.Finding, source string) string {
+	if !f.IndicatesAI() {
+		return fmt.Sprintf("- no AI disclosed (%s): %s\n", source, markdownEscape(f.Detail))
+	}
+	return fmt.Sprintf("- **%s** %s (%s): %s\n", f.Confidence, markdownEscape(f.Tool), source, markdownEscape(f.Detail))
+}
+
+// FormatMarkdownFindings writes the findings of a text scan, such as of a
+// pull request body, as a Markdown section to follow FormatMarkdown's
+// output. It stays within MarkdownTextLimit.
+func FormatMarkdownFindings(w io.Writer, findings []detection.Finding) error {
+	return formatMarkdownFindings(w, findings, MarkdownTextLimit)
+}
+
+func formatMarkdownFindings(w io.Writer, findings []detection.Finding, limit int) error {
+	var buf bytes.Buffer
+	buf.WriteString("### Text\n\n")
+	if len(findings) == 0 {
+		buf.WriteString("No AI involvement detected.\n")
+	} else {
+		signals := 0
+		entries := make([]string, len(findings))
+		for i, f := range findings {
+			if f.IndicatesAI() {
+				signals++
+			}
+			entries[i] = markdownFinding(f, f.Detector)
+		}
+		fmt.Fprintf(&buf, "Found %d AI signal(s).\n", signals)
+		writeMarkdownDetails(&buf, fmt.Sprintf("Findings (%d)", len(findings)), entries, "finding(s)", limit-buf.Len())
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// markdownEscaper escapes the characters that would otherwise format text
+// taken from commits, or mention users and link issues from a comment.
+var markdownEscaper = strings.NewReplacer(
+	"\\", "\\\\", "`", "\\`", "*", "\\*", "_", "\\_", "[", "\\[", "]", "\\]",
+	"<", "&lt;", ">", "&gt;", "|", "\\|", "#", "\\#", "@", "\\@", "~", "\\~",
+	"\r", " ", "\n", " ",
+)
+
+func markdownEscape(s string) string {
+	return markdownEscaper.Replace(s)
+}
+
+// markdownWriter buffers the commits of a streamed scan, since the tool table
+// comes before them.
+type markdownWriter struct {
+	w       io.Writer
+	commits []scan.CommitResult
+}
+
+// NewMarkdownWriter returns a ReportWriter that produces the same document as
+// FormatMarkdown. Only commits with findings are held until Close.
+func NewMarkdownWriter(w io.Writer) ReportWriter {
+	return &markdownWriter{w: w}
+}
+
+func (m *markdownWriter) WriteCommit(cr scan.CommitResult) error {
+	if len(cr.Findings) > 0 || len(cr.Recorded) > 0 {
+		m.commits = append(m.commits, cr)
+	}
+	return nil
+}
+
+func (m *markdownWriter) Close(summary scan.Summary, _ *scan.Metadata) error {
+	return FormatMarkdown(m.w, scan.Report{Commits: m.commits, Summary: summary})
+}
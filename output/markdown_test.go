@@ -0,0 +1,127 @@
+package output
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/scan"
+)
+
+func TestFormatMarkdown(t *testing.T) {
+	report := sarifReport()
+	report.Commits[0].Subject = "fix: update handler for <b>@octocat</b>"
+
+	var buf bytes.Buffer
+	if err := FormatMarkdown(&buf, report); err != nil {
+		t.Fatalf("FormatMarkdown: %v", err)
+	}
+	checkGolden(t, "scan.md", buf.Bytes())
+
+	out := buf.String()
+	if !strings.Contains(out, "| Cursor | 2 | 1 | 1 | 0 |") {
+		t.Errorf("expected Cursor row counting its findings by confidence, got:\n%s", out)
+	}
+	if !strings.Contains(out, "fix: update handler for &lt;b&gt;\\@octocat&lt;/b&gt;") {
+		t.Errorf("expected the subject escaped, got:\n%s", out)
+	}
+}
+
+func TestFormatMarkdownNoFindings(t *testing.T) {
+	var buf bytes.Buffer
+	report := scan.Report{
+		Commits: []scan.CommitResult{{Hash: "abc123def456"}},
+		Summary: scan.Summary{TotalCommits: 1, ToolCounts: map[string]int{}, ByConfidence: map[string]int{}},
+	}
+	if err := FormatMarkdown(&buf, report); err != nil {
+		t.Fatalf("FormatMarkdown: %v", err)
+	}
+	want := "## AI detection\n\nScanned 1 commit(s), 0 with AI signals.\n"
+	if buf.String() != want {
+		t.Errorf("got:\n%q\nwant:\n%q", buf.String(), want)
+	}
+}
+
+func TestFormatMarkdownTruncates(t *testing.T) {
+	report := scan.Report{Summary: scan.NewSummary()}
+	for i := range 50 {
+		cr := scan.CommitResult{
+			Hash:    fmt.Sprintf("%040x", i),
+			Subject: strings.Repeat("x", 100),
+			Findings: []detection.Finding{
+				{Detector: "coauthor", Tool: "Claude Code", Confidence: detection.ConfidenceHigh, Detail: "Co-Authored-By trailer"},
+			},
+		}
+		report.Commits = append(report.Commits, cr)
+		report.Summary.Add(cr)
+	}
+
+	const limit = 2000
+	var buf bytes.Buffer
+	if err := formatMarkdown(&buf, report, limit); err != nil {
+		t.Fatalf("formatMarkdown: %v", err)
+	}
+	out := buf.String()
+	if buf.Len() > limit {
+		t.Errorf("output is %d bytes, over the limit of %d", buf.Len(), limit)
+	}
+	if !strings.Contains(out, "| Claude Code | 50 | 50 | 0 | 0 |") {
+		t.Errorf("expected the full tool table, got:\n%s", out)
+	}
+	shown := strings.Count(out, "\n- `")
+	if shown == 0 || shown == 50 {
+		t.Fatalf("showed %d of 50 commits, want some", shown)
+	}
+	if !strings.Contains(out, fmt.Sprintf("_%d more commit(s) not shown", 50-shown)) {
+		t.Errorf("expected a note on the commits left out, got:\n%s", out)
+	}
+	if !strings.HasSuffix(out, "</details>\n") {
+		t.Errorf("expected the details section closed, got:\n%s", out)
+	}
+
+	// With no room for even one commit, only the note remains.
+	buf.Reset()
+	if err := formatMarkdown(&buf, report, 400); err != nil {
+		t.Fatalf("formatMarkdown: %v", err)
+	}
+	if strings.Contains(buf.String(), "<details>") || !strings.Contains(buf.String(), "_50 commit(s) with findings not shown") {
+		t.Errorf("expected only a note, got:\n%s", buf.String())
+	}
+}
+
+func TestFormatMarkdownFindings(t *testing.T) {
+	findings := []detection.Finding{
+		{Detector: "toolmention", Tool: "Claude Code", Confidence: detection.ConfidenceLow, Detail: "mentions Claude Code"},
+		{Detector: "disclosure", Confidence: detection.ConfidenceHigh, Detail: "AI-assisted trailer: no", Kind: detection.KindNegativeDisclosure},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatMarkdownFindings(&buf, findings); err != nil {
+		t.Fatalf("FormatMarkdownFindings: %v", err)
+	}
+	checkGolden(t, "text.md", buf.Bytes())
+
+	buf.Reset()
+	if err := FormatMarkdownFindings(&buf, nil); err != nil {
+		t.Fatalf("FormatMarkdownFindings: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No AI involvement detected.") {
+		t.Errorf("expected no-detection message, got:\n%s", buf.String())
+	}
+}
+
+func TestMarkdownWriterMatchesFormatMarkdown(t *testing.T) {
+	report := sarifReport()
+
+	var want, got bytes.Buffer
+	if err := FormatMarkdown(&want, report); err != nil {
+		t.Fatalf("FormatMarkdown: %v", err)
+	}
+	writeReport(t, NewMarkdownWriter(&got), report)
+
+	if got.String() != want.String() {
+		t.Errorf("streamed Markdown differs from FormatMarkdown:\ngot:\n%s\nwant:\n%s", got.String(), want.String())
+	}
+}
@@ -0,0 +1,68 @@
+package output
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/chaoss/ai-detection-action/scan"
+)
+
+// NDJSONVersion is the version of the NDJSON record format. It is bumped when
+// a change would break existing consumers, such as a field being removed or
+// changing meaning; new fields and record types may be added without it.
+const NDJSONVersion = 1
+
+// NDJSON record types.
+const (
+	RecordCommit   = "commit"   // Commit holds the result for one scanned commit
+	RecordMetadata = "metadata" // Metadata says what produced the scan; just before the summary
+	RecordSummary  = "summary"  // Summary holds the totals; always the last record
+)
+
+// NDJSONRecord is one line of NDJSON output. Type says which of the other
+// fields is set, and Version is NDJSONVersion.
+type NDJSONRecord struct {
+	Type     string             `json:"type"`
+	Version  int                `json:"version"`
+	Commit   *scan.CommitResult `json:"commit,omitempty"`
+	Metadata *scan.Metadata     `json:"metadata,omitempty"`
+	Summary  *scan.Summary      `json:"summary,omitempty"`
+}
+
+// ndjsonWriter writes each record on its own line as soon as it is known.
+type ndjsonWriter struct {
+	enc *json.Encoder
+}
+
+// NewNDJSONWriter returns a ReportWriter that writes newline-delimited JSON:
+// a "commit" record per commit, in scan order, a "metadata" record if there
+// is metadata, then one "summary" record.
+// Each line is written as soon as its commit is scanned, so consumers can
+// process a long scan while it runs.
+func NewNDJSONWriter(w io.Writer) ReportWriter {
+	return &ndjsonWriter{enc: json.NewEncoder(w)}
+}
+
+func (n *ndjsonWriter) WriteCommit(cr scan.CommitResult) error {
+	return n.enc.Encode(NDJSONRecord{Type: RecordCommit, Version: NDJSONVersion, Commit: &cr})
+}
+
+func (n *ndjsonWriter) Close(summary scan.Summary, meta *scan.Metadata) error {
+	if meta != nil {
+		if err := n.enc.Encode(NDJSONRecord{Type: RecordMetadata, Version: NDJSONVersion, Metadata: meta}); err != nil {
+			return err
+		}
+	}
+	return n.enc.Encode(NDJSONRecord{Type: RecordSummary, Version: NDJSONVersion, Summary: &summary})
+}
+
+// FormatNDJSON writes the report as NewNDJSONWriter does.
+func FormatNDJSON(w io.Writer, report scan.Report) error {
+	nw := NewNDJSONWriter(w)
+	for _, cr := range report.Commits {
+		if err := nw.WriteCommit(cr); err != nil {
+			return err
+		}
+	}
+	return nw.Close(report.Summary, report.Metadata)
+}
@@ -0,0 +1,95 @@
+package output
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/chaoss/ai-detection-action/scan"
+)
+
+func TestFormatNDJSON(t *testing.T) {
+	report := sampleReport()
+
+	var buf bytes.Buffer
+	if err := FormatNDJSON(&buf, report); err != nil {
+		t.Fatalf("FormatNDJSON: %v", err)
+	}
+
+	var records []NDJSONRecord
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var r NDJSONRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("line %d: %v: %s", len(records)+1, err, scanner.Text())
+		}
+		records = append(records, r)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 2 commits and a summary", len(records))
+	}
+	for i, r := range records {
+		if r.Version != NDJSONVersion {
+			t.Errorf("record %d: version %d, want %d", i, r.Version, NDJSONVersion)
+		}
+	}
+	if records[0].Type != RecordCommit || records[0].Commit.Hash != "abc123def456" || len(records[0].Commit.Findings) != 1 {
+		t.Errorf("first record = %+v", records[0])
+	}
+	if records[1].Type != RecordCommit || records[1].Commit.Hash != "def789ghi012" || records[1].Summary != nil {
+		t.Errorf("second record = %+v", records[1])
+	}
+	if last := records[2]; last.Type != RecordSummary || last.Commit != nil || last.Summary.AICommits != 1 {
+		t.Errorf("last record = %+v", last)
+	}
+}
+
+func TestFormatNDJSONMetadata(t *testing.T) {
+	report := sampleReport()
+	report.Metadata = sampleMetadata()
+
+	var buf bytes.Buffer
+	if err := FormatNDJSON(&buf, report); err != nil {
+		t.Fatalf("FormatNDJSON: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 2 commits, metadata and a summary", len(lines))
+	}
+	var meta, summary NDJSONRecord
+	if err := json.Unmarshal([]byte(lines[2]), &meta); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if err := json.Unmarshal([]byte(lines[3]), &summary); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if meta.Type != RecordMetadata || meta.Metadata.ToolVersion != "1.2.3" {
+		t.Errorf("metadata record = %+v", meta)
+	}
+	if summary.Type != RecordSummary {
+		t.Errorf("last record type = %q, want %q", summary.Type, RecordSummary)
+	}
+}
+
+func TestNDJSONWriterStreams(t *testing.T) {
+	var buf bytes.Buffer
+	rw := NewNDJSONWriter(&buf)
+
+	if err := rw.WriteCommit(sampleReport().Commits[0]); err != nil {
+		t.Fatalf("WriteCommit: %v", err)
+	}
+	// The commit is written in full before the scan ends.
+	want := `{"type":"commit","version":1,"commit":{"hash":"abc123def456",`
+	if !strings.HasPrefix(buf.String(), want) || !strings.HasSuffix(buf.String(), "}\n") {
+		t.Errorf("after one commit got %q, want a line starting %q", buf.String(), want)
+	}
+
+	if err := rw.Close(scan.NewSummary(), nil); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if n := strings.Count(buf.String(), "\n"); n != 2 {
+		t.Errorf("got %d lines, want 2", n)
+	}
+}
@@ -5,14 +5,17 @@ import (
 	"fmt"
 	"io"
 	"sort"
-	"strings"
 
+	"github.com/chaoss/ai-detection-action/attribution"
 	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/profile"
 	"github.com/chaoss/ai-detection-action/scan"
 )
 
-// FormatJSON writes the report as JSON to w.
+// FormatJSON writes the report as JSON to w, as version
+// scan.ReportSchemaVersion of the report format.
 func FormatJSON(w io.Writer, report scan.Report) error {
+	report.SchemaVersion = scan.ReportSchemaVersion
 	enc := json.NewEncoder(w)
 	enc.SetIndent("", "  ")
 	return enc.Encode(report)
@@ -20,33 +23,55 @@ func FormatJSON(w io.Writer, report scan.Report) error {
 
 // FormatText writes a human-readable summary to w.
 func FormatText(w io.Writer, report scan.Report) error {
-	fmt.Fprintf(w, "Scanned %d commits, %d with AI signals\n\n", report.Summary.TotalCommits, report.Summary.AICommits)
+	writeTextSummary(w, report.Summary)
 
-	if report.Summary.AICommits == 0 {
-		fmt.Fprintln(w, "No AI involvement detected.")
+	if report.Summary.AICommits == 0 && report.Summary.NegativeDisclosures == 0 {
 		return nil
 	}
 
-	// Tool summary
-	tools := sortedKeys(report.Summary.ToolCounts)
-	fmt.Fprintln(w, "Tools detected:")
-	for _, tool := range tools {
-		fmt.Fprintf(w, "  %s: %d\n", tool, report.Summary.ToolCounts[tool])
-	}
-	fmt.Fprintln(w)
-
 	// Per-commit detail
 	for _, cr := range report.Commits {
-		if len(cr.Findings) == 0 {
-			continue
+		writeTextCommit(w, cr)
+	}
+
+	return nil
+}
+
+func writeTextSummary(w io.Writer, summary scan.Summary) {
+	fmt.Fprintf(w, "Scanned %d commits, %d with AI signals\n\n", summary.TotalCommits, summary.AICommits)
+
+	if summary.AICommits == 0 {
+		fmt.Fprintln(w, "No AI involvement detected.")
+		if summary.NegativeDisclosures == 0 {
+			return
 		}
-		fmt.Fprintf(w, "Commit %s\n", cr.Hash[:12])
-		for _, f := range cr.Findings {
-			fmt.Fprintf(w, "  [%s] %s (%s): %s\n", f.Confidence, f.Tool, f.Detector, f.Detail)
+		fmt.Fprintln(w)
+	} else {
+		// Tool summary
+		tools := sortedKeys(summary.ToolCounts)
+		fmt.Fprintln(w, "Tools detected:")
+		for _, tool := range tools {
+			fmt.Fprintf(w, "  %s: %d\n", tool, summary.ToolCounts[tool])
 		}
+		fmt.Fprintln(w)
 	}
 
-	return nil
+	if n := summary.NegativeDisclosures; n > 0 {
+		fmt.Fprintf(w, "%d commit(s) explicitly disclose no AI assistance\n\n", n)
+	}
+}
+
+func writeTextCommit(w io.Writer, cr scan.CommitResult) {
+	if len(cr.Findings) == 0 && len(cr.Recorded) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Commit %s\n", shortHash(cr.Hash))
+	for _, f := range cr.Findings {
+		writeFinding(w, f)
+	}
+	for _, f := range cr.Recorded {
+		writeFindingFrom(w, f, f.Detector+", recorded in notes")
+	}
 }
 
 // FormatTextFindings writes findings (from a text scan) in human-readable form.
@@ -56,13 +81,34 @@ func FormatTextFindings(w io.Writer, findings []detection.Finding) error {
 		return nil
 	}
 
-	fmt.Fprintf(w, "Found %d AI signal(s):\n", len(findings))
+	signals := 0
 	for _, f := range findings {
-		fmt.Fprintf(w, "  [%s] %s (%s): %s\n", f.Confidence, f.Tool, f.Detector, f.Detail)
+		if f.IndicatesAI() {
+			signals++
+		}
+	}
+
+	fmt.Fprintf(w, "Found %d AI signal(s):\n", signals)
+	for _, f := range findings {
+		writeFinding(w, f)
 	}
 	return nil
 }
 
+func writeFinding(w io.Writer, f detection.Finding) {
+	writeFindingFrom(w, f, f.Detector)
+}
+
+// writeFindingFrom writes f with source in the parentheses that normally
+// name its detector.
+func writeFindingFrom(w io.Writer, f detection.Finding, source string) {
+	if !f.IndicatesAI() {
+		fmt.Fprintf(w, "  [no AI disclosed] (%s): %s\n", source, f.Detail)
+		return
+	}
+	fmt.Fprintf(w, "  [%s] %s (%s): %s\n", f.Confidence, f.Tool, source, f.Detail)
+}
+
 // FormatJSONFindings writes findings as JSON to w.
 func FormatJSONFindings(w io.Writer, findings []detection.Finding) error {
 	enc := json.NewEncoder(w)
@@ -72,6 +118,147 @@ func FormatJSONFindings(w io.Writer, findings []detection.Finding) error {
 	}{Findings: findings})
 }
 
+// FormatProfileJSON writes a repository profile as JSON to w.
+func FormatProfileJSON(w io.Writer, report profile.Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// FormatProfileText writes a repository profile in human-readable form.
+func FormatProfileText(w io.Writer, report profile.Report) error {
+	fmt.Fprintf(w, "Profiled %s, %d AI tool(s) set up\n\n", shortHash(report.Commit), len(report.Tools))
+
+	if len(report.Tools) == 0 {
+		fmt.Fprintln(w, "No AI tool configuration found.")
+		return nil
+	}
+
+	for _, tp := range report.Tools {
+		fmt.Fprintf(w, "%s\n", tp.Tool)
+		for _, item := range tp.Items {
+			if item.Detail != "" {
+				fmt.Fprintf(w, "  [%s] %s: %s\n", item.Kind, item.Path, item.Detail)
+				continue
+			}
+			fmt.Fprintf(w, "  [%s] %s\n", item.Kind, item.Path)
+		}
+	}
+	return nil
+}
+
+// FormatAttributionJSON writes a line attribution report as JSON to w.
+func FormatAttributionJSON(w io.Writer, report attribution.Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// FormatAttributionText writes a line attribution report in human-readable
+// form: the totals, then each directory and file.
+func FormatAttributionText(w io.Writer, report attribution.Report) error {
+	fmt.Fprintf(w, "Attributed %s, %d lines in %d file(s)\n", shortHash(report.Commit), report.Total.Total, len(report.Files))
+	fmt.Fprintf(w, "Lines from AI-flagged commits: %s\n", attributionPercents(report.Total))
+
+	for _, section := range []struct {
+		title   string
+		entries []attribution.Entry
+	}{
+		{"Directories", report.Directories},
+		{"Files", report.Files},
+	} {
+		if len(section.entries) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", section.title)
+		for _, e := range section.entries {
+			fmt.Fprintf(w, "  %s: %d lines, %s\n", e.Path, e.Total, attributionPercents(e.Lines))
+		}
+	}
+	return nil
+}
+
+// FormatDiffJSON writes a report diff as JSON to w.
+func FormatDiffJSON(w io.Writer, diff scan.ReportDiff) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(diff)
+}
+
+// FormatDiffText writes a report diff in human-readable form: the counts and
+// summary deltas, then each commit whose findings differ, with removed
+// findings marked "-", added "+" and those of changed confidence "~".
+func FormatDiffText(w io.Writer, diff scan.ReportDiff) error {
+	changes := map[string]int{}
+	for _, cd := range diff.Commits {
+		changes[cd.Change]++
+	}
+	fmt.Fprintf(w, "Compared %d commits: %d gained AI signals, %d lost them, %d changed; %d regression(s)\n",
+		diff.Compared, changes[scan.ChangeGained], changes[scan.ChangeLost], changes[scan.ChangeChanged], diff.Regressions)
+	if diff.OnlyOld > 0 || diff.OnlyNew > 0 {
+		fmt.Fprintf(w, "Not compared: %d commit(s) only in the old report, %d only in the new\n", diff.OnlyOld, diff.OnlyNew)
+	}
+
+	old, delta := diff.Summary.Old, diff.Summary.Delta
+	fmt.Fprintf(w, "AI commits: %d -> %d (%+d)\n", old.AICommits, old.AICommits+delta.AICommits, delta.AICommits)
+	for _, counts := range []struct {
+		title string
+		delta map[string]int
+	}{
+		{"Tools", delta.ToolCounts},
+		{"Confidence", delta.ByConfidence},
+	} {
+		if len(counts.delta) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "%s:", counts.title)
+		for _, k := range sortedKeys(counts.delta) {
+			fmt.Fprintf(w, " %s %+d", k, counts.delta[k])
+		}
+		fmt.Fprintln(w)
+	}
+	if n := delta.NegativeDisclosures; n != 0 {
+		fmt.Fprintf(w, "Negative disclosures: %+d\n", n)
+	}
+
+	for _, cd := range diff.Commits {
+		fmt.Fprintf(w, "\nCommit %s", shortHash(cd.Hash))
+		if cd.Subject != "" {
+			fmt.Fprintf(w, " %s", cd.Subject)
+		}
+		switch {
+		case cd.Change != "" && cd.Regression:
+			fmt.Fprintf(w, " (%s, regression)", cd.Change)
+		case cd.Change != "":
+			fmt.Fprintf(w, " (%s)", cd.Change)
+		}
+		fmt.Fprintln(w)
+		for _, f := range cd.Removed {
+			fmt.Fprint(w, "-")
+			writeFinding(w, f)
+		}
+		for _, f := range cd.Added {
+			fmt.Fprint(w, "+")
+			writeFinding(w, f)
+		}
+		for _, c := range cd.Changed {
+			fmt.Fprintf(w, "~  [%s -> %s] %s (%s): %s\n", c.Old.Confidence, c.New.Confidence, c.New.Tool, c.New.Detector, c.New.Detail)
+		}
+	}
+	return nil
+}
+
+func attributionPercents(l attribution.Lines) string {
+	return fmt.Sprintf("%.1f%% low, %.1f%% medium, %.1f%% high", l.Percent["low"], l.Percent["medium"], l.Percent["high"])
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 12 {
+		return hash[:12]
+	}
+	return hash
+}
+
 func sortedKeys(m map[string]int) []string {
 	keys := make([]string, 0, len(m))
 	for k := range m {
@@ -83,14 +270,5 @@ func sortedKeys(m map[string]int) []string {
 
 // ConfidenceFromString parses a confidence string or numeric value.
 func ConfidenceFromString(s string) (detection.Confidence, error) {
-	switch strings.ToLower(strings.TrimSpace(s)) {
-	case "1", "low":
-		return detection.ConfidenceLow, nil
-	case "2", "medium":
-		return detection.ConfidenceMedium, nil
-	case "3", "high":
-		return detection.ConfidenceHigh, nil
-	default:
-		return 0, fmt.Errorf("invalid confidence %q: use low/1, medium/2, or high/3", s)
-	}
+	return detection.ParseConfidence(s)
 }
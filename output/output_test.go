@@ -6,7 +6,10 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/chaoss/ai-detection-action/attribution"
 	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/detection/catalog"
+	"github.com/chaoss/ai-detection-action/profile"
 	"github.com/chaoss/ai-detection-action/scan"
 )
 
@@ -104,6 +107,31 @@ func TestFormatTextNoFindings(t *testing.T) {
 	}
 }
 
+func TestFormatTextRecorded(t *testing.T) {
+	var buf bytes.Buffer
+	commits := []scan.CommitResult{{
+		Hash: "abc123def4567890",
+		Recorded: []detection.Finding{{
+			Detector:   "coauthor",
+			Tool:       "Claude Code",
+			Confidence: detection.ConfidenceHigh,
+			Detail:     "Co-Authored-By trailer",
+		}},
+	}}
+
+	if err := FormatText(&buf, scan.Report{Commits: commits, Summary: scan.Summarize(commits)}); err != nil {
+		t.Fatalf("FormatText: %v", err)
+	}
+
+	want := "Commit abc123def456\n  [high] Claude Code (coauthor, recorded in notes): Co-Authored-By trailer\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("expected recorded finding in output, got:\n%s", buf.String())
+	}
+	if !strings.Contains(buf.String(), "1 with AI signals") {
+		t.Errorf("expected the recorded finding to count, got:\n%s", buf.String())
+	}
+}
+
 func TestFormatJSONFindings(t *testing.T) {
 	var buf bytes.Buffer
 	findings := []detection.Finding{
@@ -177,3 +205,215 @@ func TestConfidenceFromString(t *testing.T) {
 		}
 	}
 }
+
+func TestFormatTextNegativeDisclosure(t *testing.T) {
+	var buf bytes.Buffer
+	report := scan.Report{
+		Commits: []scan.CommitResult{
+			{
+				Hash: "abc123def456",
+				Findings: []detection.Finding{
+					{Detector: "disclosure", Confidence: detection.ConfidenceHigh, Detail: "AI-assisted trailer: no", Kind: detection.KindNegativeDisclosure},
+				},
+			},
+		},
+		Summary: scan.Summary{
+			TotalCommits:        1,
+			ToolCounts:          map[string]int{},
+			ByConfidence:        map[string]int{},
+			NegativeDisclosures: 1,
+		},
+	}
+
+	if err := FormatText(&buf, report); err != nil {
+		t.Fatalf("FormatText: %v", err)
+	}
+
+	out := buf.String()
+	if !strings.Contains(out, "No AI involvement detected") {
+		t.Errorf("expected no-detection message, got:\n%s", out)
+	}
+	if !strings.Contains(out, "1 commit(s) explicitly disclose no AI assistance") {
+		t.Errorf("expected negative disclosure count, got:\n%s", out)
+	}
+	if !strings.Contains(out, "[no AI disclosed] (disclosure): AI-assisted trailer: no") {
+		t.Errorf("expected negative disclosure detail, got:\n%s", out)
+	}
+}
+
+func sampleProfile() profile.Report {
+	return profile.Report{
+		Commit: "0123456789abcdef0123456789abcdef01234567",
+		Tools: []profile.ToolProfile{
+			{
+				Tool:   "Claude Code",
+				ToolID: "claude-code",
+				Items: []profile.Item{
+					{Path: "CLAUDE.md", Kind: catalog.ArtifactInstructions},
+					{Path: ".github/workflows/claude.yml", Kind: catalog.ArtifactWorkflow, Detail: "uses anthropics/claude-code-action@v1"},
+				},
+			},
+		},
+	}
+}
+
+func TestFormatProfileJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatProfileJSON(&buf, sampleProfile()); err != nil {
+		t.Fatalf("FormatProfileJSON: %v", err)
+	}
+
+	var decoded profile.Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if len(decoded.Tools) != 1 || decoded.Tools[0].ToolID != "claude-code" {
+		t.Fatalf("tools = %+v, want claude-code", decoded.Tools)
+	}
+	if len(decoded.Tools[0].Items) != 2 {
+		t.Errorf("got %d items, want 2", len(decoded.Tools[0].Items))
+	}
+}
+
+func TestFormatProfileText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatProfileText(&buf, sampleProfile()); err != nil {
+		t.Fatalf("FormatProfileText: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"Profiled 0123456789ab, 1 AI tool(s) set up",
+		"Claude Code\n",
+		"  [instructions] CLAUDE.md\n",
+		"  [workflow] .github/workflows/claude.yml: uses anthropics/claude-code-action@v1\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestFormatProfileTextEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatProfileText(&buf, profile.Report{Commit: "abc"}); err != nil {
+		t.Fatalf("FormatProfileText: %v", err)
+	}
+	if !strings.Contains(buf.String(), "No AI tool configuration found.") {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+}
+
+func sampleAttribution() attribution.Report {
+	lines := func(total, low, medium, high int, pLow, pMedium, pHigh float64) attribution.Lines {
+		return attribution.Lines{
+			Total:   total,
+			AI:      map[string]int{"low": low, "medium": medium, "high": high},
+			Percent: map[string]float64{"low": pLow, "medium": pMedium, "high": pHigh},
+		}
+	}
+	return attribution.Report{
+		Commit:      "0123456789abcdef0123456789abcdef01234567",
+		Total:       lines(8, 3, 3, 2, 37.5, 37.5, 25),
+		Directories: []attribution.Entry{{Path: "src", Lines: lines(5, 3, 3, 2, 60, 60, 40)}},
+		Files: []attribution.Entry{
+			{Path: "README.md", Lines: lines(3, 0, 0, 0, 0, 0, 0)},
+			{Path: "src/gen.go", Lines: lines(5, 3, 3, 2, 60, 60, 40)},
+		},
+	}
+}
+
+func TestFormatAttributionJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatAttributionJSON(&buf, sampleAttribution()); err != nil {
+		t.Fatalf("FormatAttributionJSON: %v", err)
+	}
+
+	var decoded attribution.Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded.Total.Total != 8 || decoded.Total.Percent["low"] != 37.5 {
+		t.Errorf("total = %+v", decoded.Total)
+	}
+	if len(decoded.Files) != 2 || decoded.Files[1].Path != "src/gen.go" || decoded.Files[1].AI["high"] != 2 {
+		t.Errorf("files = %+v", decoded.Files)
+	}
+}
+
+func TestFormatAttributionText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatAttributionText(&buf, sampleAttribution()); err != nil {
+		t.Fatalf("FormatAttributionText: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"Attributed 0123456789ab, 8 lines in 2 file(s)\n",
+		"Lines from AI-flagged commits: 37.5% low, 37.5% medium, 25.0% high\n",
+		"Directories:\n  src: 5 lines, 60.0% low, 60.0% medium, 40.0% high\n",
+		"Files:\n  README.md: 3 lines, 0.0% low, 0.0% medium, 0.0% high\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func sampleDiff() scan.ReportDiff {
+	aider := detection.Finding{Detector: "message", Tool: "Aider", Confidence: detection.ConfidenceMedium, Detail: "commit message matches Aider pattern"}
+	aiderLow := aider
+	aiderLow.Confidence = detection.ConfidenceLow
+	mention := detection.Finding{Detector: "toolmention", Tool: "Claude", Confidence: detection.ConfidenceLow, Detail: "text mentions Claude"}
+	return scan.ReportDiff{
+		Compared: 4,
+		Commits: []scan.CommitDiff{
+			{Hash: "abc123def4567890", Subject: "refactor", Change: scan.ChangeChanged, Regression: true, Changed: []scan.FindingChange{{Old: aider, New: aiderLow}}},
+			{Hash: "def789ghi012", Change: scan.ChangeGained, Added: []detection.Finding{mention}},
+		},
+		Summary: scan.SummaryDiff{
+			Old:   scan.Summary{TotalCommits: 4, AICommits: 1},
+			New:   scan.Summary{TotalCommits: 4, AICommits: 2},
+			Delta: scan.Summary{AICommits: 1, ToolCounts: map[string]int{"Claude": 1}, ByConfidence: map[string]int{"low": 2, "medium": -1}},
+		},
+		Regressions: 1,
+		OnlyNew:     2,
+	}
+}
+
+func TestFormatDiffJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatDiffJSON(&buf, sampleDiff()); err != nil {
+		t.Fatalf("FormatDiffJSON: %v", err)
+	}
+
+	var decoded scan.ReportDiff
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded.Regressions != 1 || len(decoded.Commits) != 2 || decoded.Commits[0].Changed[0].New.Confidence != detection.ConfidenceLow {
+		t.Errorf("decoded = %+v", decoded)
+	}
+}
+
+func TestFormatDiffText(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatDiffText(&buf, sampleDiff()); err != nil {
+		t.Fatalf("FormatDiffText: %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{
+		"Compared 4 commits: 1 gained AI signals, 0 lost them, 1 changed; 1 regression(s)\n",
+		"Not compared: 0 commit(s) only in the old report, 2 only in the new\n",
+		"AI commits: 1 -> 2 (+1)\n",
+		"Tools: Claude +1\n",
+		"Confidence: low +2 medium -1\n",
+		"\nCommit abc123def456 refactor (changed, regression)\n~  [medium -> low] Aider (message): commit message matches Aider pattern\n",
+		"\nCommit def789ghi012 (gained)\n+  [low] Claude (toolmention): text mentions Claude\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/scan"
+)
+
+// SARIF 2.1.0 identifiers.
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+)
+
+// sarifRules describes the built-in detectors, in the order their rules are
+// listed. Detectors not listed here, such as those of other programs using
+// this package, get a rule when one of their findings is reported.
+var sarifRules = []struct{ id, description string }{
+	{"committer", "Commit authored or committed by a known AI bot identity"},
+	{"coauthor", "Co-Authored-By trailer naming an AI tool"},
+	{"message", "Commit message pattern an AI tool writes"},
+	{"toolmention", "AI tool named in the text"},
+	{"disclosure", "AI assistance disclosed in a trailer"},
+	{"files", "AI tool instruction, configuration or session file changed"},
+	{"gitai", "Lines attributed to an AI agent by a git-ai authorship log"},
+	{"refs", "Commit made on an AI agent branch"},
+	{"rules", "Match of a custom detection rule"},
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool                     sarifTool                 `json:"tool"`
+	Invocations              []sarifInvocation         `json:"invocations,omitempty"`
+	VersionControlProvenance []sarifVersionControlInfo `json:"versionControlProvenance,omitempty"`
+	Results                  []sarifResult             `json:"results"`
+	Properties               *scan.Summary             `json:"properties,omitempty"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful bool   `json:"executionSuccessful"`
+	StartTimeUTC        string `json:"startTimeUtc"`
+	EndTimeUTC          string `json:"endTimeUtc"`
+}
+
+type sarifVersionControlInfo struct {
+	RepositoryURI string `json:"repositoryUri"`
+	RevisionID    string `json:"revisionId,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string       `json:"id"`
+	ShortDescription sarifMessage `json:"shortDescription"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string          `json:"ruleId"`
+	RuleIndex  int             `json:"ruleIndex"`
+	Kind       string          `json:"kind"`
+	Level      string          `json:"level"`
+	Message    sarifMessage    `json:"message"`
+	Locations  []sarifLocation `json:"locations,omitempty"`
+	Properties sarifProperties `json:"properties"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation"`
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+// repositoryRoot locates a result that concerns no file, such as one about a
+// whole commit or a pull request's text, at the root of the repository:
+// GitHub code scanning drops results without a physical location.
+func repositoryRoot() *sarifPhysicalLocation {
+	return &sarifPhysicalLocation{
+		ArtifactLocation: sarifArtifactLocation{URI: ".", URIBaseID: "%SRCROOT%"},
+		Region:           sarifRegion{StartLine: 1, EndLine: 1},
+	}
+}
+
+type sarifRegion struct {
+	StartLine int `json:"startLine"`
+	EndLine   int `json:"endLine"`
+}
+
+type sarifLogicalLocation struct {
+	Name               string `json:"name"`
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// sarifProperties carries the finding fields SARIF has no place for.
+type sarifProperties struct {
+	Tool          string `json:"tool,omitempty"`
+	ToolID        string `json:"tool_id,omitempty"`
+	Confidence    string `json:"confidence"`
+	Model         string `json:"model,omitempty"`
+	Role          string `json:"role,omitempty"`
+	SelfDisclosed bool   `json:"self_disclosed,omitempty"`
+	Recorded      bool   `json:"recorded,omitempty"` // From a git note; see scan.CommitResult
+}
+
+// sarifBuilder accumulates results and the rules they refer to.
+type sarifBuilder struct {
+	rules   []sarifRule
+	index   map[string]int
+	results []sarifResult
+}
+
+func newSARIFBuilder() *sarifBuilder {
+	b := &sarifBuilder{index: map[string]int{}}
+	for _, r := range sarifRules {
+		b.rule(r.id, r.description)
+	}
+	return b
+}
+
+// rule returns the index of the rule for detector, adding it if needed.
+func (b *sarifBuilder) rule(detector, description string) int {
+	if i, ok := b.index[detector]; ok {
+		return i
+	}
+	if description == "" {
+		description = fmt.Sprintf("Finding of the %s detector", detector)
+	}
+	b.index[detector] = len(b.rules)
+	b.rules = append(b.rules, sarifRule{ID: detector, ShortDescription: sarifMessage{Text: description}})
+	return b.index[detector]
+}
+
+// add records f, found in commit (empty for a text scan), as a result.
+func (b *sarifBuilder) add(f detection.Finding, commit string, recorded bool) {
+	result := sarifResult{
+		RuleID:    f.Detector,
+		RuleIndex: b.rule(f.Detector, ""),
+		Kind:      "fail",
+		Level:     sarifLevel(f.Confidence),
+		Message:   sarifMessage{Text: fmt.Sprintf("%s: %s", f.Tool, f.Detail)},
+		Properties: sarifProperties{
+			Tool:          f.Tool,
+			ToolID:        f.ToolID,
+			Confidence:    f.Confidence.String(),
+			Model:         f.Model,
+			Role:          f.Role,
+			SelfDisclosed: f.SelfDisclosed,
+			Recorded:      recorded,
+		},
+	}
+	if !f.IndicatesAI() {
+		result.Kind, result.Level = "informational", "none"
+		result.Message.Text = "No AI assistance disclosed: " + f.Detail
+	}
+
+	var logical []sarifLogicalLocation
+	if commit != "" {
+		logical = []sarifLogicalLocation{{Name: shortHash(commit), FullyQualifiedName: commit, Kind: "commit"}}
+	}
+	for _, file := range f.Lines {
+		for _, r := range file.Ranges {
+			result.Locations = append(result.Locations, sarifLocation{
+				PhysicalLocation: &sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLocation{URI: file.Path},
+					Region:           sarifRegion{StartLine: r.Start, EndLine: r.End},
+				},
+				LogicalLocations: logical,
+			})
+		}
+	}
+	if len(result.Locations) == 0 {
+		result.Locations = []sarifLocation{{PhysicalLocation: repositoryRoot(), LogicalLocations: logical}}
+	}
+	b.results = append(b.results, result)
+}
+
+func (b *sarifBuilder) write(w io.Writer, summary *scan.Summary, meta *scan.Metadata) error {
+	results := b.results
+	if results == nil {
+		results = []sarifResult{}
+	}
+	run := sarifRun{
+		Tool: sarifTool{Driver: sarifDriver{
+			Name:           "ai-detection",
+			InformationURI: "https://github.com/chaoss/ai-detection-action",
+			Rules:          b.rules,
+		}},
+		Results:    results,
+		Properties: summary,
+	}
+	if meta != nil {
+		run.Tool.Driver.Version = meta.ToolVersion
+		run.Invocations = []sarifInvocation{{
+			ExecutionSuccessful: true,
+			StartTimeUTC:        meta.StartedAt.UTC().Format(time.RFC3339),
+			EndTimeUTC:          meta.FinishedAt.UTC().Format(time.RFC3339),
+		}}
+		// SARIF requires a URI for the repository, which a clone without
+		// an origin remote does not have.
+		if meta.Repository.RemoteURL != "" {
+			vc := sarifVersionControlInfo{RepositoryURI: meta.Repository.RemoteURL}
+			if len(meta.Range.Include) == 1 {
+				vc.RevisionID = meta.Range.Include[0]
+			}
+			run.VersionControlProvenance = []sarifVersionControlInfo{vc}
+		}
+	}
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs:    []sarifRun{run},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+// sarifLevel maps a confidence to a SARIF result level.
+func sarifLevel(c detection.Confidence) string {
+	switch c {
+	case detection.ConfidenceHigh:
+		return "error"
+	case detection.ConfidenceMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// FormatSARIF writes the report as a SARIF 2.1.0 log with a single run. Each
+// detector is a rule and each finding a result, located at its commit by a
+// logical location of kind "commit" and, for findings that attribute lines,
+// at those lines. High confidence maps to level "error", medium to
+// "warning" and low to "note"; negative disclosures are informational. The
+// summary is attached as the run's properties, and the metadata, if any,
+// gives the tool version, the invocation's times and the repository.
+func FormatSARIF(w io.Writer, report scan.Report) error {
+	b := newSARIFBuilder()
+	for _, cr := range report.Commits {
+		for _, f := range cr.Findings {
+			b.add(f, cr.Hash, false)
+		}
+		for _, f := range cr.Recorded {
+			b.add(f, cr.Hash, true)
+		}
+	}
+	return b.write(w, &report.Summary, report.Metadata)
+}
+
+// FormatSARIFFindings writes the findings of a text scan as a SARIF 2.1.0
+// log. Their results have no location.
+func FormatSARIFFindings(w io.Writer, findings []detection.Finding) error {
+	b := newSARIFBuilder()
+	for _, f := range findings {
+		b.add(f, "", false)
+	}
+	return b.write(w, nil, nil)
+}
+
+// sarifWriter buffers the commits of a streamed scan, since a SARIF log is a
+// single document whose rules precede its results.
+type sarifWriter struct {
+	w       io.Writer
+	commits []scan.CommitResult
+}
+
+// NewSARIFWriter returns a ReportWriter that produces the same log as
+// FormatSARIF. Only commits with findings are held until Close.
+func NewSARIFWriter(w io.Writer) ReportWriter {
+	return &sarifWriter{w: w}
+}
+
+func (s *sarifWriter) WriteCommit(cr scan.CommitResult) error {
+	if len(cr.Findings) > 0 || len(cr.Recorded) > 0 {
+		s.commits = append(s.commits, cr)
+	}
+	return nil
+}
+
+func (s *sarifWriter) Close(summary scan.Summary, meta *scan.Metadata) error {
+	return FormatSARIF(s.w, scan.Report{Commits: s.commits, Summary: summary, Metadata: meta})
+}
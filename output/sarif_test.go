@@ -0,0 +1,196 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/scan"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// checkGolden compares got with testdata/name, or rewrites the file when the
+// tests run with -update.
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("write golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s:\ngot:\n%s\nwant:\n%s", path, got, want)
+	}
+}
+
+// sarifReport extends sampleReport with line-level, recorded, negative and
+// custom-detector findings.
+func sarifReport() scan.Report {
+	report := sampleReport()
+	report.Commits = append(report.Commits,
+		scan.CommitResult{
+			Hash: "0123456789abcdef0123456789abcdef01234567",
+			Findings: []detection.Finding{
+				{
+					Detector:   "gitai",
+					Tool:       "Cursor",
+					ToolID:     "cursor",
+					Model:      "gpt-5",
+					Confidence: detection.ConfidenceHigh,
+					Detail:     "git-ai authorship log attributes 4 line(s) in 2 file(s)",
+					Lines: []detection.FileLines{
+						{Path: "src/main.go", Ranges: []detection.LineRange{{Start: 1, End: 2}, {Start: 9, End: 9}}},
+						{Path: "README.md", Ranges: []detection.LineRange{{Start: 4, End: 4}}},
+					},
+				},
+				{
+					Detector:   "refs",
+					Tool:       "Cursor",
+					ToolID:     "cursor",
+					Confidence: detection.ConfidenceMedium,
+					Detail:     "made on agent branch cursor/fix",
+				},
+			},
+			Recorded: []detection.Finding{
+				{Detector: "vendor-check", Tool: "Aider", Confidence: detection.ConfidenceLow, Detail: "recorded by an earlier scan"},
+			},
+		},
+		scan.CommitResult{
+			Hash: "fedcba9876543210fedcba9876543210fedcba98",
+			Findings: []detection.Finding{
+				{Detector: "disclosure", Confidence: detection.ConfidenceHigh, Detail: "AI-assisted trailer: no", Kind: detection.KindNegativeDisclosure},
+			},
+		},
+	)
+	report.Summary = scan.Summarize(report.Commits)
+	return report
+}
+
+func TestFormatSARIF(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatSARIF(&buf, sarifReport()); err != nil {
+		t.Fatalf("FormatSARIF: %v", err)
+	}
+	checkGolden(t, "scan.sarif", buf.Bytes())
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	run := log.Runs[0]
+	for _, r := range run.Results {
+		if run.Tool.Driver.Rules[r.RuleIndex].ID != r.RuleID {
+			t.Errorf("result %q has rule index %d, which is %q", r.RuleID, r.RuleIndex, run.Tool.Driver.Rules[r.RuleIndex].ID)
+		}
+	}
+}
+
+func TestFormatSARIFMetadata(t *testing.T) {
+	report := sampleReport()
+	report.Metadata = sampleMetadata()
+
+	var buf bytes.Buffer
+	if err := FormatSARIF(&buf, report); err != nil {
+		t.Fatalf("FormatSARIF: %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	run := log.Runs[0]
+	if run.Tool.Driver.Version != "1.2.3" {
+		t.Errorf("driver version = %q", run.Tool.Driver.Version)
+	}
+	if len(run.Invocations) != 1 || run.Invocations[0].StartTimeUTC != "2024-05-01T09:00:00Z" || run.Invocations[0].EndTimeUTC != "2024-05-01T09:05:00Z" {
+		t.Errorf("invocations = %+v", run.Invocations)
+	}
+	want := sarifVersionControlInfo{RepositoryURI: "https://example.com/project.git", RevisionID: "0123456789abcdef0123456789abcdef01234567"}
+	if len(run.VersionControlProvenance) != 1 || run.VersionControlProvenance[0] != want {
+		t.Errorf("versionControlProvenance = %+v, want %+v", run.VersionControlProvenance, want)
+	}
+}
+
+func TestFormatSARIFFindings(t *testing.T) {
+	findings := []detection.Finding{
+		{Detector: "toolmention", Tool: "Claude Code", ToolID: "claude-code", Confidence: detection.ConfidenceLow, Detail: "mentions Claude Code"},
+	}
+
+	var buf bytes.Buffer
+	if err := FormatSARIFFindings(&buf, findings); err != nil {
+		t.Fatalf("FormatSARIFFindings: %v", err)
+	}
+	checkGolden(t, "text.sarif", buf.Bytes())
+}
+
+func TestSARIFWriterMatchesFormatSARIF(t *testing.T) {
+	report := sarifReport()
+
+	var want, got bytes.Buffer
+	if err := FormatSARIF(&want, report); err != nil {
+		t.Fatalf("FormatSARIF: %v", err)
+	}
+	writeReport(t, NewSARIFWriter(&got), report)
+
+	if got.String() != want.String() {
+		t.Errorf("streamed SARIF differs from FormatSARIF:\ngot:\n%s\nwant:\n%s", got.String(), want.String())
+	}
+}
+
+func TestSARIFWriterNoCommits(t *testing.T) {
+	var buf bytes.Buffer
+	writeReport(t, NewSARIFWriter(&buf), scan.Report{Summary: scan.NewSummary()})
+
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, buf.String())
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 || log.Runs[0].Results == nil || len(log.Runs[0].Results) != 0 {
+		t.Errorf("log = %+v, want one run with an empty result list", log)
+	}
+}
+
+// GitHub code scanning drops results without a physical location, so those
+// about a whole commit, with no files, fall back to the repository root.
+func TestFormatSARIFPhysicalLocations(t *testing.T) {
+	var buf bytes.Buffer
+	if err := FormatSARIF(&buf, sarifReport()); err != nil {
+		t.Fatalf("FormatSARIF: %v", err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &log); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, buf.String())
+	}
+
+	roots := 0
+	for _, r := range log.Runs[0].Results {
+		if len(r.Locations) == 0 {
+			t.Errorf("result %q has no locations", r.Message.Text)
+		}
+		for _, loc := range r.Locations {
+			if loc.PhysicalLocation == nil {
+				t.Errorf("result %q has a location without a physical location", r.Message.Text)
+				continue
+			}
+			if *loc.PhysicalLocation == *repositoryRoot() {
+				roots++
+				if len(loc.LogicalLocations) == 0 {
+					t.Errorf("result %q at the repository root lost its commit", r.Message.Text)
+				}
+			}
+		}
+	}
+	if roots == 0 {
+		t.Error("no result fell back to the repository root")
+	}
+}
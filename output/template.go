@@ -0,0 +1,116 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"text/template"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/scan"
+)
+
+// ToolCount is a tool and its number of findings, as listed by the
+// toolsByCount template function.
+type ToolCount struct {
+	Tool  string
+	Count int
+}
+
+// ToolFindings is a tool and its findings, as listed by the groupByTool
+// template function.
+type ToolFindings struct {
+	Tool     string
+	Findings []detection.Finding
+}
+
+// TemplateFuncs returns the functions available to report templates besides
+// text/template's own:
+//
+//	shortHash HASH        the first 12 characters of a commit hash
+//	confidence LEVEL      the name of a confidence level: low, medium or high
+//	toolsByCount COUNTS   the entries of a map such as .Summary.ToolCounts as
+//	                      []ToolCount, most findings first, ties by name
+//	groupByTool FINDINGS  findings as []ToolFindings, one per tool in order of
+//	                      first appearance, keeping the findings' order
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"shortHash":    shortHash,
+		"confidence":   detection.Confidence.String,
+		"toolsByCount": toolsByCount,
+		"groupByTool":  groupByTool,
+	}
+}
+
+func toolsByCount(counts map[string]int) []ToolCount {
+	tools := make([]ToolCount, 0, len(counts))
+	for _, tool := range sortedKeys(counts) {
+		tools = append(tools, ToolCount{Tool: tool, Count: counts[tool]})
+	}
+	sort.SliceStable(tools, func(i, j int) bool {
+		return tools[i].Count > tools[j].Count
+	})
+	return tools
+}
+
+func groupByTool(findings []detection.Finding) []ToolFindings {
+	var groups []ToolFindings
+	index := map[string]int{}
+	for _, f := range findings {
+		i, ok := index[f.Tool]
+		if !ok {
+			i = len(groups)
+			index[f.Tool] = i
+			groups = append(groups, ToolFindings{Tool: f.Tool})
+		}
+		groups[i].Findings = append(groups[i].Findings, f)
+	}
+	return groups
+}
+
+// LoadTemplate parses the text/template in the named file, with
+// TemplateFuncs available to it.
+func LoadTemplate(path string) (*template.Template, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return template.New(filepath.Base(path)).Funcs(TemplateFuncs()).Parse(string(data))
+}
+
+// FormatTemplate renders the report through tmpl, which is given the
+// scan.Report as its data. Nothing is written if rendering fails.
+func FormatTemplate(w io.Writer, report scan.Report, tmpl *template.Template) error {
+	report.SchemaVersion = scan.ReportSchemaVersion
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, report); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// templateWriter holds every commit of a streamed scan, since a template may
+// refer to any of them.
+type templateWriter struct {
+	w       io.Writer
+	tmpl    *template.Template
+	commits []scan.CommitResult
+}
+
+// NewTemplateWriter returns a ReportWriter that renders the report through
+// tmpl as FormatTemplate does, once the scan is done.
+func NewTemplateWriter(w io.Writer, tmpl *template.Template) ReportWriter {
+	return &templateWriter{w: w, tmpl: tmpl, commits: []scan.CommitResult{}}
+}
+
+func (t *templateWriter) WriteCommit(cr scan.CommitResult) error {
+	t.commits = append(t.commits, cr)
+	return nil
+}
+
+func (t *templateWriter) Close(summary scan.Summary, meta *scan.Metadata) error {
+	return FormatTemplate(t.w, scan.Report{Commits: t.commits, Summary: summary, Metadata: meta}, t.tmpl)
+}
@@ -0,0 +1,99 @@
+package output
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/chaoss/ai-detection-action/detection"
+)
+
+func TestFormatTemplate(t *testing.T) {
+	tmpl, err := LoadTemplate(filepath.Join("testdata", "report.tmpl"))
+	if err != nil {
+		t.Fatalf("LoadTemplate: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := FormatTemplate(&buf, csvReport(), tmpl); err != nil {
+		t.Fatalf("FormatTemplate: %v", err)
+	}
+	checkGolden(t, "report.txt", buf.Bytes())
+
+	var streamed bytes.Buffer
+	writeReport(t, NewTemplateWriter(&streamed, tmpl), csvReport())
+	if streamed.String() != buf.String() {
+		t.Errorf("template writer differs from FormatTemplate:\ngot:\n%s\nwant:\n%s", streamed.String(), buf.String())
+	}
+}
+
+func TestFormatTemplateData(t *testing.T) {
+	report := sampleReport()
+	report.Metadata = sampleMetadata()
+	tmpl := template.Must(template.New("data").Funcs(TemplateFuncs()).Parse(
+		`{{.SchemaVersion}} {{len .Commits}} {{(index .Commits 0).Hash}} {{.Metadata.ToolVersion}} {{confidence 3}}`))
+
+	var buf bytes.Buffer
+	if err := FormatTemplate(&buf, report, tmpl); err != nil {
+		t.Fatalf("FormatTemplate: %v", err)
+	}
+	if want := "1 2 abc123def456 1.2.3 high"; buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestFormatTemplateError(t *testing.T) {
+	tmpl := template.Must(template.New("bad").Parse(`partial {{.Missing}}`))
+
+	var buf bytes.Buffer
+	if err := FormatTemplate(&buf, sampleReport(), tmpl); err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+	if buf.Len() != 0 {
+		t.Errorf("wrote %q despite the error", buf.String())
+	}
+}
+
+func TestLoadTemplateErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := LoadTemplate(filepath.Join(dir, "missing.tmpl")); err == nil {
+		t.Error("expected error for missing file")
+	}
+	path := filepath.Join(dir, "broken.tmpl")
+	if err := os.WriteFile(path, []byte("{{range .Commits}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadTemplate(path); err == nil || !strings.Contains(err.Error(), "broken.tmpl") {
+		t.Errorf("expected a parse error naming the file, got %v", err)
+	}
+}
+
+func TestToolsByCount(t *testing.T) {
+	got := toolsByCount(map[string]int{"Cursor": 1, "Aider": 3, "Claude": 3})
+	want := []ToolCount{{"Aider", 3}, {"Claude", 3}, {"Cursor", 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("toolsByCount = %v, want %v", got, want)
+	}
+}
+
+func TestGroupByTool(t *testing.T) {
+	findings := []detection.Finding{
+		{Detector: "message", Tool: "Aider"},
+		{Detector: "coauthor", Tool: "Claude Code"},
+		{Detector: "toolmention", Tool: "Aider"},
+	}
+	groups := groupByTool(findings)
+	if len(groups) != 2 || groups[0].Tool != "Aider" || groups[1].Tool != "Claude Code" {
+		t.Fatalf("groups = %+v", groups)
+	}
+	if len(groups[0].Findings) != 2 || groups[0].Findings[1].Detector != "toolmention" {
+		t.Errorf("Aider findings = %+v", groups[0].Findings)
+	}
+	if groupByTool(nil) != nil {
+		t.Error("groupByTool(nil) should be empty")
+	}
+}
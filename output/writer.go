@@ -0,0 +1,128 @@
+package output
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/chaoss/ai-detection-action/scan"
+)
+
+// ReportWriter writes a scan report one commit at a time, so results appear
+// while a long scan is still running. Close writes the summary and, in
+// formats with a place for it, the metadata, which may be nil; it must be
+// called once, after the last commit.
+type ReportWriter interface {
+	WriteCommit(cr scan.CommitResult) error
+	Close(summary scan.Summary, meta *scan.Metadata) error
+}
+
+// NewReportWriter returns a ReportWriter for the named format ("json",
+// "ndjson", "text", "sarif", "markdown", or "csv" and "tsv" with a row per
+// finding).
+func NewReportWriter(w io.Writer, format string) (ReportWriter, error) {
+	switch format {
+	case "json":
+		return NewJSONWriter(w), nil
+	case "ndjson":
+		return NewNDJSONWriter(w), nil
+	case "text":
+		return NewTextWriter(w), nil
+	case "sarif":
+		return NewSARIFWriter(w), nil
+	case "markdown":
+		return NewMarkdownWriter(w), nil
+	case "csv":
+		return NewCSVWriter(w, ',', RowsFindings), nil
+	case "tsv":
+		return NewCSVWriter(w, '\t', RowsFindings), nil
+	default:
+		return nil, fmt.Errorf("unknown format: %s", format)
+	}
+}
+
+// jsonWriter streams the same document FormatJSON writes, emitting each
+// commit as soon as it is written.
+type jsonWriter struct {
+	w       *bufio.Writer
+	started bool
+}
+
+// NewJSONWriter returns a ReportWriter that produces the same JSON document
+// as FormatJSON.
+func NewJSONWriter(w io.Writer) ReportWriter {
+	return &jsonWriter{w: bufio.NewWriter(w)}
+}
+
+func (j *jsonWriter) WriteCommit(cr scan.CommitResult) error {
+	data, err := json.MarshalIndent(cr, "    ", "  ")
+	if err != nil {
+		return err
+	}
+	if j.started {
+		j.w.WriteString(",\n")
+	} else {
+		j.startDocument()
+		j.w.WriteString("  \"commits\": [\n")
+		j.started = true
+	}
+	j.w.WriteString("    ")
+	j.w.Write(data)
+	// Flush per commit so output keeps up with the scan.
+	return j.w.Flush()
+}
+
+func (j *jsonWriter) Close(summary scan.Summary, meta *scan.Metadata) error {
+	data, err := json.MarshalIndent(summary, "  ", "  ")
+	if err != nil {
+		return err
+	}
+	if j.started {
+		j.w.WriteString("\n  ],\n")
+	} else {
+		j.startDocument()
+		j.w.WriteString("  \"commits\": [],\n")
+	}
+	j.w.WriteString("  \"summary\": ")
+	j.w.Write(data)
+	if meta != nil {
+		data, err := json.MarshalIndent(meta, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		j.w.WriteString(",\n  \"metadata\": ")
+		j.w.Write(data)
+	}
+	j.w.WriteString("\n}\n")
+	return j.w.Flush()
+}
+
+func (j *jsonWriter) startDocument() {
+	fmt.Fprintf(j.w, "{\n  \"schema_version\": %d,\n", scan.ReportSchemaVersion)
+}
+
+// textWriter prints each commit's findings as it is scanned and the summary
+// at the end.
+type textWriter struct {
+	w io.Writer
+}
+
+// NewTextWriter returns a ReportWriter that prints findings per commit as they
+// arrive, followed by the summary FormatText starts with.
+func NewTextWriter(w io.Writer) ReportWriter {
+	return &textWriter{w: w}
+}
+
+func (t *textWriter) WriteCommit(cr scan.CommitResult) error {
+	writeTextCommit(t.w, cr)
+	return nil
+}
+
+func (t *textWriter) Close(summary scan.Summary, _ *scan.Metadata) error {
+	if summary.AICommits > 0 || summary.NegativeDisclosures > 0 {
+		fmt.Fprintln(t.w)
+	}
+	writeTextSummary(t.w, summary)
+	return nil
+}
@@ -0,0 +1,115 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chaoss/ai-detection-action/gitops"
+	"github.com/chaoss/ai-detection-action/scan"
+)
+
+func writeReport(t *testing.T, rw ReportWriter, report scan.Report) {
+	t.Helper()
+	for _, cr := range report.Commits {
+		if err := rw.WriteCommit(cr); err != nil {
+			t.Fatalf("WriteCommit: %v", err)
+		}
+	}
+	if err := rw.Close(report.Summary, report.Metadata); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+func TestJSONWriterMatchesFormatJSON(t *testing.T) {
+	report := sampleReport()
+
+	var want, got bytes.Buffer
+	if err := FormatJSON(&want, report); err != nil {
+		t.Fatalf("FormatJSON: %v", err)
+	}
+	writeReport(t, NewJSONWriter(&got), report)
+
+	if got.String() != want.String() {
+		t.Errorf("streamed JSON differs from FormatJSON:\ngot:\n%s\nwant:\n%s", got.String(), want.String())
+	}
+}
+
+func sampleMetadata() *scan.Metadata {
+	return &scan.Metadata{
+		ToolVersion: "1.2.3",
+		Repository:  scan.Repository{Path: "/src/project", RemoteURL: "https://example.com/project.git"},
+		Range: scan.Range{Spec: "v1..main", ResolvedRange: gitops.ResolvedRange{
+			Include: []string{"0123456789abcdef0123456789abcdef01234567"},
+			Exclude: []string{"fedcba9876543210fedcba9876543210fedcba98"},
+		}},
+		Detectors:  scan.Detectors{Names: []string{"coauthor", "message"}, SignatureVersion: "1", Catalog: "c", Fingerprint: "f"},
+		Filters:    scan.Filters{MinConfidence: "medium"},
+		StartedAt:  time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC),
+		FinishedAt: time.Date(2024, 5, 1, 9, 5, 0, 0, time.UTC),
+	}
+}
+
+func TestJSONWriterMetadata(t *testing.T) {
+	report := sampleReport()
+	report.Metadata = sampleMetadata()
+
+	var want, got bytes.Buffer
+	if err := FormatJSON(&want, report); err != nil {
+		t.Fatalf("FormatJSON: %v", err)
+	}
+	writeReport(t, NewJSONWriter(&got), report)
+	if got.String() != want.String() {
+		t.Errorf("streamed JSON differs from FormatJSON:\ngot:\n%s\nwant:\n%s", got.String(), want.String())
+	}
+
+	var decoded scan.Report
+	if err := json.Unmarshal(got.Bytes(), &decoded); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if decoded.SchemaVersion != scan.ReportSchemaVersion {
+		t.Errorf("schema_version = %d, want %d", decoded.SchemaVersion, scan.ReportSchemaVersion)
+	}
+	if decoded.Metadata == nil || decoded.Metadata.Range.Include[0] != report.Metadata.Range.Include[0] || !decoded.Metadata.FinishedAt.Equal(report.Metadata.FinishedAt) {
+		t.Errorf("metadata = %+v, want %+v", decoded.Metadata, report.Metadata)
+	}
+}
+
+func TestJSONWriterNoCommits(t *testing.T) {
+	var buf bytes.Buffer
+	writeReport(t, NewJSONWriter(&buf), scan.Report{Summary: scan.NewSummary()})
+
+	var decoded scan.Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("unmarshal: %v\n%s", err, buf.String())
+	}
+	if decoded.Commits == nil || len(decoded.Commits) != 0 {
+		t.Errorf("commits = %v, want an empty list", decoded.Commits)
+	}
+}
+
+func TestTextWriter(t *testing.T) {
+	var buf bytes.Buffer
+	writeReport(t, NewTextWriter(&buf), sampleReport())
+
+	out := buf.String()
+	commit := strings.Index(out, "Commit abc123def456")
+	summary := strings.Index(out, "Scanned 2 commits, 1 with AI signals")
+	if commit < 0 || summary < 0 {
+		t.Fatalf("missing commit or summary:\n%s", out)
+	}
+	if commit > summary {
+		t.Errorf("expected commits before the summary:\n%s", out)
+	}
+	if !strings.Contains(out, "Claude Code: 1") {
+		t.Errorf("expected tool counts in output:\n%s", out)
+	}
+}
+
+func TestNewReportWriterUnknownFormat(t *testing.T) {
+	if _, err := NewReportWriter(&bytes.Buffer{}, "xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
@@ -0,0 +1,160 @@
+// Package profile inventories how a repository is set up for AI tools at
+// HEAD: agent instruction files, tool and MCP configuration, CI workflows
+// that run AI actions, and .gitignore entries for AI tool state.
+package profile
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/chaoss/ai-detection-action/detection/catalog"
+	"github.com/chaoss/ai-detection-action/gitops"
+)
+
+// Item is one piece of evidence that a tool is set up in the repository.
+type Item struct {
+	Path   string               `json:"path"`
+	Kind   catalog.ArtifactKind `json:"kind"`
+	Detail string               `json:"detail,omitempty"`
+}
+
+// ToolProfile lists everything found for one tool.
+type ToolProfile struct {
+	Tool   string `json:"tool"`
+	ToolID string `json:"tool_id"`
+	Items  []Item `json:"items"`
+}
+
+// Report is the AI profile of a repository at one commit.
+type Report struct {
+	Commit string        `json:"commit"`
+	Tools  []ToolProfile `json:"tools"`
+}
+
+var usesPattern = regexp.MustCompile(`(?m)^[\s-]*uses:\s*["']?([^\s"'#]+)`)
+
+// Profile builds the AI profile of the repository at repoPath from the tree
+// HEAD points to.
+func Profile(repoPath string) (Report, error) {
+	tree, err := gitops.HeadTree(repoPath)
+	if err != nil {
+		return Report{}, err
+	}
+
+	paths, err := tree.Files()
+	if err != nil {
+		return Report{}, err
+	}
+
+	b := newBuilder()
+	for _, p := range paths {
+		if a, ok := catalog.MatchArtifact(p); ok {
+			b.add(a.ToolID, Item{Path: p, Kind: a.Kind})
+		}
+
+		switch {
+		case isWorkflow(p):
+			contents, err := tree.ReadFile(p)
+			if err != nil {
+				return Report{}, err
+			}
+			for _, uses := range workflowUses(contents) {
+				if a, ok := catalog.MatchWorkflowAction(uses); ok {
+					b.add(a.ToolID, Item{Path: p, Kind: catalog.ArtifactWorkflow, Detail: "uses " + uses})
+				}
+			}
+		case path.Base(p) == ".gitignore":
+			contents, err := tree.ReadFile(p)
+			if err != nil {
+				return Report{}, err
+			}
+			for _, entry := range ignorePatterns(contents) {
+				if e, ok := catalog.MatchIgnoreEntry(normalizeIgnore(entry)); ok {
+					b.add(e.ToolID, Item{Path: p, Kind: catalog.ArtifactIgnore, Detail: "ignores " + entry})
+				}
+			}
+		}
+	}
+
+	return Report{Commit: tree.Commit, Tools: b.tools()}, nil
+}
+
+func isWorkflow(p string) bool {
+	dir, file := path.Split(p)
+	ext := path.Ext(file)
+	return dir == ".github/workflows/" && (ext == ".yml" || ext == ".yaml")
+}
+
+// workflowUses returns the "uses" value of every step in a workflow file.
+// Matching lines rather than decoding YAML keeps malformed workflows from
+// hiding an action.
+func workflowUses(contents string) []string {
+	var out []string
+	for _, m := range usesPattern.FindAllStringSubmatch(contents, -1) {
+		out = append(out, m[1])
+	}
+	return out
+}
+
+// ignorePatterns returns the patterns in a .gitignore file, skipping blank
+// lines and comments.
+func ignorePatterns(contents string) []string {
+	var out []string
+	for _, line := range strings.Split(contents, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		out = append(out, line)
+	}
+	return out
+}
+
+// normalizeIgnore strips the parts of a .gitignore pattern that do not
+// change which tool it refers to: negation, anchoring and directory markers.
+// A negated entry such as "!.claude/settings.json" still shows the tool is in
+// use.
+func normalizeIgnore(pattern string) string {
+	pattern = strings.TrimPrefix(pattern, "!")
+	pattern = strings.TrimPrefix(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "**/")
+	return strings.TrimSuffix(pattern, "/")
+}
+
+type builder struct {
+	byTool map[string]*ToolProfile
+	seen   map[string]bool
+}
+
+func newBuilder() *builder {
+	return &builder{byTool: map[string]*ToolProfile{}, seen: map[string]bool{}}
+}
+
+func (b *builder) add(toolID string, item Item) {
+	key := fmt.Sprintf("%s\x00%s\x00%s", toolID, item.Path, item.Detail)
+	if b.seen[key] {
+		return
+	}
+	b.seen[key] = true
+
+	tp, ok := b.byTool[toolID]
+	if !ok {
+		tool := catalog.ToolFor(toolID)
+		tp = &ToolProfile{Tool: tool.Name, ToolID: tool.ID}
+		b.byTool[toolID] = tp
+	}
+	tp.Items = append(tp.Items, item)
+}
+
+// tools returns the profiles sorted by tool name, with items in tree order.
+func (b *builder) tools() []ToolProfile {
+	out := make([]ToolProfile, 0, len(b.byTool))
+	for _, tp := range b.byTool {
+		out = append(out, *tp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Tool < out[j].Tool })
+	return out
+}
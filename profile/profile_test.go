@@ -0,0 +1,153 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/chaoss/ai-detection-action/detection/catalog"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// initTestRepo commits files (path -> contents) in a single commit.
+func initTestRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+
+	for name, contents := range files {
+		full := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(contents), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if _, err := wt.Add(name); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+	}
+
+	_, err = wt.Commit("initial commit", &git.CommitOptions{
+		Author: &object.Signature{Name: "Test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	return dir
+}
+
+func findTool(r Report, toolID string) (ToolProfile, bool) {
+	for _, tp := range r.Tools {
+		if tp.ToolID == toolID {
+			return tp, true
+		}
+	}
+	return ToolProfile{}, false
+}
+
+func TestProfile(t *testing.T) {
+	dir := initTestRepo(t, map[string]string{
+		"README.md":        "# demo\n",
+		"CLAUDE.md":        "Use gofmt.\n",
+		".mcp.json":        "{}\n",
+		"AGENTS.md":        "Run the tests.\n",
+		".cursor/mcp.json": "{}\n",
+		".github/workflows/claude.yml": `on: issue_comment
+jobs:
+  claude:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - uses: anthropics/claude-code-action@v1
+`,
+		".github/workflows/copilot-setup-steps.yml": "on: workflow_dispatch\n",
+		".gitignore": "# local state\nnode_modules/\n/.claude/settings.local.json\n.aider*\n",
+	})
+
+	r, err := Profile(dir)
+	if err != nil {
+		t.Fatalf("Profile: %v", err)
+	}
+	if len(r.Commit) != 40 {
+		t.Errorf("commit = %q, want a full hash", r.Commit)
+	}
+
+	claude, ok := findTool(r, "claude-code")
+	if !ok {
+		t.Fatalf("claude-code not in profile: %+v", r.Tools)
+	}
+	want := []Item{
+		{Path: ".github/workflows/claude.yml", Kind: catalog.ArtifactWorkflow, Detail: "uses anthropics/claude-code-action@v1"},
+		{Path: ".gitignore", Kind: catalog.ArtifactIgnore, Detail: "ignores /.claude/settings.local.json"},
+		{Path: ".mcp.json", Kind: catalog.ArtifactMCP},
+		{Path: "CLAUDE.md", Kind: catalog.ArtifactInstructions},
+	}
+	if len(claude.Items) != len(want) {
+		t.Fatalf("claude-code items = %+v, want %+v", claude.Items, want)
+	}
+	for i := range want {
+		if claude.Items[i] != want[i] {
+			t.Errorf("items[%d] = %+v, want %+v", i, claude.Items[i], want[i])
+		}
+	}
+
+	for _, id := range []string{"unspecified", "cursor", "copilot-agent", "aider"} {
+		if _, ok := findTool(r, id); !ok {
+			t.Errorf("%s not in profile: %+v", id, r.Tools)
+		}
+	}
+	if len(r.Tools) != 5 {
+		t.Errorf("got %d tools, want 5: %+v", len(r.Tools), r.Tools)
+	}
+}
+
+func TestProfileNoAI(t *testing.T) {
+	dir := initTestRepo(t, map[string]string{
+		"main.go":                  "package main\n",
+		".gitignore":               "bin/\n",
+		".github/workflows/ci.yml": "steps:\n  - uses: actions/setup-go@v5\n",
+		"docs/claude-notes.txt":    "not an artifact\n",
+	})
+
+	r, err := Profile(dir)
+	if err != nil {
+		t.Fatalf("Profile: %v", err)
+	}
+	if len(r.Tools) != 0 {
+		t.Errorf("got %d tools, want 0: %+v", len(r.Tools), r.Tools)
+	}
+}
+
+func TestProfileInvalidRepo(t *testing.T) {
+	if _, err := Profile(t.TempDir()); err == nil {
+		t.Error("expected error for non-repository")
+	}
+}
+
+func TestNormalizeIgnore(t *testing.T) {
+	cases := map[string]string{
+		".claude/":               ".claude",
+		"/.cursor":               ".cursor",
+		"**/.aider*":             ".aider*",
+		"!.claude/settings.json": ".claude/settings.json",
+		"CLAUDE.local.md":        "CLAUDE.local.md",
+	}
+	for in, want := range cases {
+		if got := normalizeIgnore(in); got != want {
+			t.Errorf("normalizeIgnore(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
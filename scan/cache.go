@@ -0,0 +1,109 @@
+package scan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/detection/catalog"
+)
+
+// cacheFormat is bumped when the layout of cache entries changes.
+const cacheFormat = "3"
+
+// Cache stores commit results on disk so that later scans skip commits they
+// have already seen. Commits are immutable, so an entry only goes stale when
+// the detectors change; entries live under a directory named by Fingerprint,
+// and a different detector set, rules file, catalog or signature version
+// simply reads and writes a different directory. One cache directory can be
+// shared by scans of many repositories, including concurrent ones.
+type Cache struct {
+	dir string
+}
+
+// OpenCache returns the cache in dir for results produced by detectors,
+// creating the directory if needed.
+func OpenCache(dir string, detectors []detection.Detector) (*Cache, error) {
+	c := &Cache{dir: filepath.Join(dir, Fingerprint(detectors))}
+	if err := os.MkdirAll(c.dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating cache: %w", err)
+	}
+	return c, nil
+}
+
+// Fingerprint identifies the results detectors produce: their names and
+// order, the configuration of any detection.Fingerprinted detector, the tool
+// catalog and detection.SignatureVersion. Volatile detectors are left out,
+// as their findings are never stored.
+func Fingerprint(detectors []detection.Detector) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "format %s\nsignatures %s\ncatalog %s\n", cacheFormat, detection.SignatureVersion, catalog.Fingerprint())
+	for _, d := range detectors {
+		if detection.IsVolatile(d) {
+			continue
+		}
+		fmt.Fprintf(h, "detector %q", d.Name())
+		if fp, ok := d.(detection.Fingerprinted); ok {
+			fmt.Fprintf(h, " %q", fp.Fingerprint())
+		}
+		fmt.Fprintln(h)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:32]
+}
+
+func (c *Cache) path(hash string) string {
+	if len(hash) < 3 {
+		return filepath.Join(c.dir, hash+".json")
+	}
+	return filepath.Join(c.dir, hash[:2], hash[2:]+".json")
+}
+
+// Get returns the cached result for the commit hash. An entry that cannot be
+// read or decoded counts as a miss and is rescanned.
+func (c *Cache) Get(hash string) (CommitResult, bool) {
+	data, err := os.ReadFile(c.path(hash))
+	if err != nil {
+		return CommitResult{}, false
+	}
+	var r CommitResult
+	if err := json.Unmarshal(data, &r); err != nil || r.Hash != hash {
+		return CommitResult{}, false
+	}
+	return r, true
+}
+
+// Put stores r. The entry is written to a temporary file and renamed into
+// place, so concurrent scans never read a partial entry.
+func (c *Cache) Put(r CommitResult) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	path := c.path(r.Hash)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("writing cache: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,279 @@
+package scan
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"testing"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/detection/gitai"
+	"github.com/chaoss/ai-detection-action/detection/message"
+	"github.com/chaoss/ai-detection-action/detection/rules"
+	"github.com/chaoss/ai-detection-action/detection/toolmention"
+	"github.com/chaoss/ai-detection-action/gitops"
+)
+
+// callsDetector counts its Detect calls and finds nothing.
+type callsDetector struct {
+	calls atomic.Int32
+}
+
+func (d *callsDetector) Name() string          { return "calls" }
+func (d *callsDetector) ConcurrencySafe() bool { return true }
+
+func (d *callsDetector) Detect(detection.Input) []detection.Finding {
+	d.calls.Add(1)
+	return nil
+}
+
+// volatileDetector stands in for a detector such as refs, whose findings
+// depend on more than the commit. It reports every commit it is given a
+// repository path for, and is not safe for concurrent use.
+type volatileDetector struct {
+	calls int
+}
+
+func (d *volatileDetector) Name() string   { return "volatile" }
+func (d *volatileDetector) Volatile() bool { return true }
+
+func (d *volatileDetector) Detect(input detection.Input) []detection.Finding {
+	d.calls++
+	if input.CommitHash == "" || input.RepoPath == "" {
+		return nil
+	}
+	return []detection.Finding{{Detector: d.Name(), Tool: "Acme", Confidence: detection.ConfidenceLow, Detail: "seen in " + input.RepoPath}}
+}
+
+func TestCacheRoundTrip(t *testing.T) {
+	cache, err := OpenCache(t.TempDir(), allDetectors())
+	if err != nil {
+		t.Fatalf("OpenCache: %v", err)
+	}
+
+	want := CommitResult{
+		Hash: "0123456789abcdef0123456789abcdef01234567",
+		Findings: []detection.Finding{{
+			Detector:   "message",
+			Tool:       "Aider",
+			ToolID:     "aider",
+			Confidence: detection.ConfidenceMedium,
+			Detail:     "aider: prefix",
+			Role:       detection.RoleAuthor,
+		}},
+	}
+	if _, ok := cache.Get(want.Hash); ok {
+		t.Fatal("Get before Put: unexpected hit")
+	}
+	if err := cache.Put(want); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	got, ok := cache.Get(want.Hash)
+	if !ok {
+		t.Fatal("Get after Put: miss")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Get = %+v, want %+v", got, want)
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	ruleSet := func(email string) detection.Detector {
+		d, err := rules.Compile(rules.File{Rules: []rules.Rule{{Tool: "Acme", Confidence: "high", Email: email}}})
+		if err != nil {
+			t.Fatalf("compile rules: %v", err)
+		}
+		return d
+	}
+
+	base := Fingerprint(allDetectors())
+	if base != Fingerprint(allDetectors()) {
+		t.Error("fingerprint is not stable")
+	}
+	if base == Fingerprint(allDetectors()[1:]) {
+		t.Error("fingerprint ignores the detector set")
+	}
+
+	if base != Fingerprint(append(allDetectors(), &volatileDetector{})) {
+		t.Error("fingerprint includes a volatile detector")
+	}
+
+	withA := Fingerprint(append(allDetectors(), ruleSet("a@acme.test")))
+	withB := Fingerprint(append(allDetectors(), ruleSet("b@acme.test")))
+	if withA == base || withA == withB {
+		t.Error("fingerprint ignores the rules")
+	}
+	if withA != Fingerprint(append(allDetectors(), ruleSet("a@acme.test"))) {
+		t.Error("fingerprint differs for identical rules")
+	}
+}
+
+func TestScannerCache(t *testing.T) {
+	dir := initLongRepo(t, 12)
+
+	for _, jobs := range []int{1, 4} {
+		t.Run(fmt.Sprintf("jobs=%d", jobs), func(t *testing.T) {
+			calls := &callsDetector{}
+			detectors := append(allDetectors(), calls)
+			cache, err := OpenCache(t.TempDir(), detectors)
+			if err != nil {
+				t.Fatalf("OpenCache: %v", err)
+			}
+			scanner := &Scanner{Detectors: detectors, Jobs: jobs, Cache: cache}
+
+			// Scanning part of the history first leaves the rest for the
+			// full scan.
+			if _, err := scanner.ScanCommitRange(dir, "HEAD~4..HEAD"); err != nil {
+				t.Fatalf("partial scan: %v", err)
+			}
+			calls.calls.Store(0)
+			cached, err := scanner.ScanCommitRange(dir, "")
+			if err != nil {
+				t.Fatalf("cached scan: %v", err)
+			}
+			if got := calls.calls.Load(); got != 8 {
+				t.Errorf("jobs=%d: detectors ran on %d commits, want the 8 not yet cached", jobs, got)
+			}
+
+			calls.calls.Store(0)
+			again, err := scanner.ScanCommitRange(dir, "")
+			if err != nil {
+				t.Fatalf("rescan: %v", err)
+			}
+			if got := calls.calls.Load(); got != 0 {
+				t.Errorf("jobs=%d: detectors ran on %d commits of a fully cached range", jobs, got)
+			}
+
+			fresh, err := (&Scanner{Detectors: detectors}).ScanCommitRange(dir, "")
+			if err != nil {
+				t.Fatalf("uncached scan: %v", err)
+			}
+			for _, r := range []Report{cached, again} {
+				if !reflect.DeepEqual(r, fresh) {
+					t.Errorf("jobs=%d: cached report differs from an uncached scan", jobs)
+				}
+			}
+		})
+	}
+}
+
+func TestScannerCacheVolatile(t *testing.T) {
+	dir := initLongRepo(t, 6)
+
+	for _, jobs := range []int{1, 4} {
+		t.Run(fmt.Sprintf("jobs=%d", jobs), func(t *testing.T) {
+			calls, volatile := &callsDetector{}, &volatileDetector{}
+			detectors := append(allDetectors(), calls, volatile)
+			cache, err := OpenCache(t.TempDir(), detectors)
+			if err != nil {
+				t.Fatalf("OpenCache: %v", err)
+			}
+			scanner := &Scanner{Detectors: detectors, Jobs: jobs, Cache: cache}
+
+			fresh, err := scanner.ScanCommitRange(dir, "")
+			if err != nil {
+				t.Fatalf("scan: %v", err)
+			}
+			for _, r := range fresh.Commits {
+				entry, ok := cache.Get(r.Hash)
+				if !ok {
+					t.Fatalf("commit %s not cached", r.Hash[:7])
+				}
+				for _, f := range entry.Findings {
+					if f.Detector == "volatile" {
+						t.Errorf("commit %s: volatile finding was cached", r.Hash[:7])
+					}
+				}
+			}
+
+			calls.calls.Store(0)
+			volatile.calls = 0
+			cached, err := scanner.ScanCommitRange(dir, "")
+			if err != nil {
+				t.Fatalf("cached scan: %v", err)
+			}
+			if calls.calls.Load() != 0 || volatile.calls != 6 {
+				t.Errorf("cached scan ran the detectors on %d commits and the volatile one on %d, want 0 and 6", calls.calls.Load(), volatile.calls)
+			}
+			if !reflect.DeepEqual(cached, fresh) {
+				t.Errorf("cached report differs from the first scan:\n%+v\nvs\n%+v", cached, fresh)
+			}
+		})
+	}
+}
+
+// A volatile detector listed before others still gives the same findings,
+// in the same order, whether a result is scanned or taken from the cache.
+func TestScannerCacheVolatileOrder(t *testing.T) {
+	dir := initLongRepo(t, 6)
+
+	for _, jobs := range []int{1, 4} {
+		t.Run(fmt.Sprintf("jobs=%d", jobs), func(t *testing.T) {
+			detectors := []detection.Detector{&volatileDetector{}, &message.Detector{}, &toolmention.Detector{}}
+			cache, err := OpenCache(t.TempDir(), detectors)
+			if err != nil {
+				t.Fatalf("OpenCache: %v", err)
+			}
+			scanner := &Scanner{Detectors: detectors, Jobs: jobs, Cache: cache}
+
+			fresh, err := scanner.ScanCommitRange(dir, "")
+			if err != nil {
+				t.Fatalf("scan: %v", err)
+			}
+			cached, err := scanner.ScanCommitRange(dir, "")
+			if err != nil {
+				t.Fatalf("cached scan: %v", err)
+			}
+			if !reflect.DeepEqual(cached, fresh) {
+				t.Errorf("cached report differs from the first scan:\n%+v\nvs\n%+v", cached, fresh)
+			}
+			if f := fresh.Commits[0].Findings; f[len(f)-1].Detector != "volatile" {
+				t.Errorf("findings = %+v, want the volatile one last", f)
+			}
+		})
+	}
+}
+
+// An authorship log fetched after a commit was cached or noted is still read.
+func TestScannerAuthorshipLogAfterCache(t *testing.T) {
+	dir, hashes := initTestRepo(t)
+	detectors := append(allDetectors(), &gitai.Detector{})
+	cache, err := OpenCache(t.TempDir(), detectors)
+	if err != nil {
+		t.Fatalf("OpenCache: %v", err)
+	}
+
+	first, err := (&Scanner{Detectors: detectors, Cache: cache}).ScanCommitRange(dir, "")
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	notes := map[string][]byte{}
+	for _, r := range first.Commits {
+		if notes[r.Hash], err = NewNote(r, detectors).Marshal(); err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+	}
+	if err := gitops.WriteNotes(dir, DefaultNotesRef, notes, "scan\n"); err != nil {
+		t.Fatalf("WriteNotes: %v", err)
+	}
+
+	log := "file0.txt\n  0a1b2c3d4e5f6a7b 1\n---\n" +
+		`{"schema_version": "authorship/3.0.0", "prompts": {"0a1b2c3d4e5f6a7b": {"agent_id": {"tool": "cursor", "model": "gpt-5"}}}}` + "\n"
+	if err := gitops.WriteNotes(dir, gitops.AuthorshipNotesRef, map[string][]byte{hashes[0]: []byte(log)}, "git-ai\n"); err != nil {
+		t.Fatalf("WriteNotes: %v", err)
+	}
+
+	for name, scanner := range map[string]*Scanner{
+		"cache": {Detectors: detectors, Cache: cache},
+		"notes": {Detectors: detectors, Jobs: 4, NotesRef: DefaultNotesRef},
+	} {
+		report, err := scanner.ScanCommitRange(dir, "")
+		if err != nil {
+			t.Fatalf("%s: scan: %v", name, err)
+		}
+		initial := report.Commits[len(report.Commits)-1]
+		if initial.Hash != hashes[0] || len(initial.Findings) != 1 || initial.Findings[0].ToolID != "cursor" {
+			t.Errorf("%s: initial commit = %+v, want the authorship log's finding", name, initial)
+		}
+	}
+}
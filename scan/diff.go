@@ -0,0 +1,252 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chaoss/ai-detection-action/detection"
+)
+
+// How a commit's classification changed between two reports.
+const (
+	ChangeGained  = "gained"  // No AI signal before, some now
+	ChangeLost    = "lost"    // AI signals before, none now
+	ChangeChanged = "changed" // AI signals in both, but different tools or confidence
+)
+
+// ReportDiff lists the commits whose findings differ between two reports of
+// the same commits, e.g. before and after a tool upgrade or a rules change.
+type ReportDiff struct {
+	Compared int          `json:"compared"` // Commits in both reports
+	Commits  []CommitDiff `json:"commits"`
+	Summary  SummaryDiff  `json:"summary"`
+	// Regressions counts the commits that lost a finding that indicated AI
+	// or had one's confidence lowered.
+	Regressions int `json:"regressions"`
+	// Commits in only one of the reports, which are not compared.
+	OnlyOld int `json:"only_old,omitempty"`
+	OnlyNew int `json:"only_new,omitempty"`
+}
+
+// CommitDiff holds the findings that differ for one commit. Findings are
+// matched by detector, tool and kind; a match whose confidence differs is
+// listed under Changed rather than as removed and added.
+type CommitDiff struct {
+	Hash       string              `json:"hash"`
+	Subject    string              `json:"subject,omitempty"`
+	Change     string              `json:"change,omitempty"` // ChangeGained, ChangeLost or ChangeChanged; empty if only negative disclosures differ
+	Regression bool                `json:"regression"`
+	Added      []detection.Finding `json:"added,omitempty"`
+	Removed    []detection.Finding `json:"removed,omitempty"`
+	Changed    []FindingChange     `json:"changed,omitempty"`
+}
+
+// FindingChange is a finding whose confidence differs between the reports.
+type FindingChange struct {
+	Old detection.Finding `json:"old"`
+	New detection.Finding `json:"new"`
+}
+
+// SummaryDiff holds both summaries, and in Delta the new counts minus the
+// old. Tools and confidence levels whose counts did not change are left out
+// of Delta's maps.
+type SummaryDiff struct {
+	Old   Summary `json:"old"`
+	New   Summary `json:"new"`
+	Delta Summary `json:"delta"`
+}
+
+// ReadReport reads a JSON report as written by scan --format=json. Reports
+// from a newer schema version than ReportSchemaVersion are rejected, since
+// they may mean something this version cannot tell.
+func ReadReport(r io.Reader) (Report, error) {
+	var report Report
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return Report{}, fmt.Errorf("decode report: %w", err)
+	}
+	if report.SchemaVersion > ReportSchemaVersion {
+		return Report{}, fmt.Errorf("report schema version %d is newer than supported version %d", report.SchemaVersion, ReportSchemaVersion)
+	}
+	return report, nil
+}
+
+// LoadReport reads the JSON report in the named file.
+func LoadReport(path string) (Report, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Report{}, err
+	}
+	defer f.Close()
+
+	report, err := ReadReport(f)
+	if err != nil {
+		return Report{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return report, nil
+}
+
+// Diff compares the commits found in both old and new, in new's order.
+// Recorded findings count the same as the commit's own, as in Summarize.
+func Diff(old, new Report) ReportDiff {
+	oldCommits := make(map[string]CommitResult, len(old.Commits))
+	for _, cr := range old.Commits {
+		oldCommits[cr.Hash] = cr
+	}
+
+	d := ReportDiff{Commits: []CommitDiff{}}
+	seen := make(map[string]bool, len(new.Commits))
+	for _, cr := range new.Commits {
+		seen[cr.Hash] = true
+		before, ok := oldCommits[cr.Hash]
+		if !ok {
+			d.OnlyNew++
+			continue
+		}
+		d.Compared++
+		cd, differs := diffCommit(before, cr)
+		if !differs {
+			continue
+		}
+		if cd.Regression {
+			d.Regressions++
+		}
+		d.Commits = append(d.Commits, cd)
+	}
+	for _, cr := range old.Commits {
+		if !seen[cr.Hash] {
+			d.OnlyOld++
+		}
+	}
+
+	d.Summary = SummaryDiff{Old: old.Summary, New: new.Summary, Delta: summaryDelta(old.Summary, new.Summary)}
+	return d
+}
+
+// findingKey identifies a finding across reports.
+type findingKey struct {
+	detector, tool string
+	kind           detection.Kind
+}
+
+func keyOf(f detection.Finding) findingKey {
+	return findingKey{f.Detector, f.Tool, f.Kind}
+}
+
+// allFindings returns the findings of cr and its recorded ones.
+func allFindings(cr CommitResult) []detection.Finding {
+	if len(cr.Recorded) == 0 {
+		return cr.Findings
+	}
+	return append(append([]detection.Finding(nil), cr.Findings...), cr.Recorded...)
+}
+
+// diffCommit compares the findings of one commit in two reports, and
+// reports whether they differ.
+func diffCommit(old, new CommitResult) (CommitDiff, bool) {
+	cd := CommitDiff{Hash: new.Hash, Subject: new.Subject}
+	if cd.Subject == "" {
+		cd.Subject = old.Subject
+	}
+
+	before := allFindings(old)
+	after := allFindings(new)
+	matched := make([]bool, len(before))
+	for _, f := range after {
+		i := matchFinding(before, matched, f)
+		if i < 0 {
+			cd.Added = append(cd.Added, f)
+			continue
+		}
+		matched[i] = true
+		if before[i].Confidence == f.Confidence {
+			continue
+		}
+		cd.Changed = append(cd.Changed, FindingChange{Old: before[i], New: f})
+		if f.IndicatesAI() && f.Confidence < before[i].Confidence {
+			cd.Regression = true
+		}
+	}
+	for i, f := range before {
+		if matched[i] {
+			continue
+		}
+		cd.Removed = append(cd.Removed, f)
+		if f.IndicatesAI() {
+			cd.Regression = true
+		}
+	}
+	if len(cd.Added) == 0 && len(cd.Removed) == 0 && len(cd.Changed) == 0 {
+		return cd, false
+	}
+
+	wasAI, isAI := detection.AnyIndicatesAI(before), detection.AnyIndicatesAI(after)
+	switch {
+	case !wasAI && isAI:
+		cd.Change = ChangeGained
+	case wasAI && !isAI:
+		cd.Change = ChangeLost
+	case wasAI && isAI && signalsDiffer(cd):
+		cd.Change = ChangeChanged
+	}
+	return cd, true
+}
+
+// matchFinding returns the index of the first unmatched finding in findings
+// with f's key, preferring one of the same confidence, or -1 if there is none.
+func matchFinding(findings []detection.Finding, matched []bool, f detection.Finding) int {
+	match := -1
+	for i, g := range findings {
+		if matched[i] || keyOf(g) != keyOf(f) {
+			continue
+		}
+		if g.Confidence == f.Confidence {
+			return i
+		}
+		if match < 0 {
+			match = i
+		}
+	}
+	return match
+}
+
+// signalsDiffer reports whether any of the differences in cd is in a finding
+// that indicates AI, rather than in a negative disclosure.
+func signalsDiffer(cd CommitDiff) bool {
+	if detection.AnyIndicatesAI(cd.Added) || detection.AnyIndicatesAI(cd.Removed) {
+		return true
+	}
+	for _, c := range cd.Changed {
+		if c.New.IndicatesAI() {
+			return true
+		}
+	}
+	return false
+}
+
+// summaryDelta returns new minus old, leaving out unchanged counts.
+func summaryDelta(old, new Summary) Summary {
+	return Summary{
+		TotalCommits:        new.TotalCommits - old.TotalCommits,
+		AICommits:           new.AICommits - old.AICommits,
+		ToolCounts:          countsDelta(old.ToolCounts, new.ToolCounts),
+		ByConfidence:        countsDelta(old.ByConfidence, new.ByConfidence),
+		NegativeDisclosures: new.NegativeDisclosures - old.NegativeDisclosures,
+	}
+}
+
+func countsDelta(old, new map[string]int) map[string]int {
+	delta := map[string]int{}
+	for k, n := range new {
+		if n != old[k] {
+			delta[k] = n - old[k]
+		}
+	}
+	for k, n := range old {
+		if _, ok := new[k]; !ok {
+			delta[k] = -n
+		}
+	}
+	return delta
+}
@@ -0,0 +1,126 @@
+package scan
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chaoss/ai-detection-action/detection"
+)
+
+func diffReports() (Report, Report) {
+	coauthor := detection.Finding{Detector: "coauthor", Tool: "Claude Code", Confidence: detection.ConfidenceHigh, Detail: "trailer"}
+	mention := detection.Finding{Detector: "toolmention", Tool: "Claude", Confidence: detection.ConfidenceLow, Detail: "mention"}
+	aider := detection.Finding{Detector: "message", Tool: "Aider", Confidence: detection.ConfidenceMedium, Detail: "pattern"}
+	noAI := detection.Finding{Detector: "disclosure", Tool: "None", Confidence: detection.ConfidenceHigh, Detail: "AI-Assisted: no", Kind: detection.KindNegativeDisclosure}
+
+	old := []CommitResult{
+		{Hash: "aaa", Subject: "unchanged", Findings: []detection.Finding{coauthor}},
+		{Hash: "bbb", Subject: "lost", Findings: []detection.Finding{aider}},
+		{Hash: "ccc", Subject: "gained"},
+		{Hash: "ddd", Subject: "downgraded", Findings: []detection.Finding{coauthor, mention}},
+		{Hash: "eee", Subject: "disclosure"},
+		{Hash: "old", Subject: "only old"},
+	}
+	aiderLow := aider
+	aiderLow.Confidence = detection.ConfidenceLow
+	new := []CommitResult{
+		{Hash: "new", Subject: "only new"},
+		{Hash: "aaa", Subject: "unchanged", Findings: []detection.Finding{coauthor}},
+		{Hash: "bbb", Subject: "lost"},
+		{Hash: "ccc", Subject: "gained", Findings: []detection.Finding{mention}},
+		{Hash: "ddd", Subject: "downgraded", Recorded: []detection.Finding{coauthor}, Findings: []detection.Finding{mention, aiderLow}},
+		{Hash: "eee", Subject: "disclosure", Findings: []detection.Finding{noAI}},
+	}
+	return Report{Commits: old, Summary: Summarize(old)}, Report{Commits: new, Summary: Summarize(new)}
+}
+
+func TestDiff(t *testing.T) {
+	d := Diff(diffReports())
+
+	if d.Compared != 5 || d.OnlyOld != 1 || d.OnlyNew != 1 {
+		t.Errorf("compared %d, only old %d, only new %d; want 5, 1, 1", d.Compared, d.OnlyOld, d.OnlyNew)
+	}
+	if d.Regressions != 1 {
+		t.Errorf("regressions = %d, want 1", d.Regressions)
+	}
+
+	var got []string
+	for _, cd := range d.Commits {
+		got = append(got, cd.Hash+":"+cd.Change)
+	}
+	if want := "bbb:lost ccc:gained ddd:changed eee:"; strings.Join(got, " ") != want {
+		t.Fatalf("commits = %v, want %s", got, want)
+	}
+
+	lost := d.Commits[0]
+	if !lost.Regression || len(lost.Removed) != 1 || lost.Removed[0].Tool != "Aider" {
+		t.Errorf("lost commit = %+v", lost)
+	}
+	// A finding that moved to the recorded list is unchanged, and a new
+	// finding is not a regression.
+	changed := d.Commits[2]
+	if changed.Regression || len(changed.Added) != 1 || len(changed.Removed) != 0 || len(changed.Changed) != 0 {
+		t.Errorf("changed commit = %+v", changed)
+	}
+	if d.Commits[1].Regression || d.Commits[3].Regression {
+		t.Error("gained signals and negative disclosures are not regressions")
+	}
+
+	delta := d.Summary.Delta
+	if delta.AICommits != 0 || delta.ToolCounts["Aider"] != 0 || delta.ToolCounts["Claude"] != 1 || delta.NegativeDisclosures != 1 {
+		t.Errorf("delta = %+v", delta)
+	}
+	if _, ok := delta.ToolCounts["Claude Code"]; ok {
+		t.Errorf("delta lists unchanged tool count: %v", delta.ToolCounts)
+	}
+}
+
+func TestDiffDowngrade(t *testing.T) {
+	high := detection.Finding{Detector: "message", Tool: "Aider", Confidence: detection.ConfidenceHigh}
+	low := high
+	low.Confidence = detection.ConfidenceLow
+	old := Report{Commits: []CommitResult{{Hash: "aaa", Findings: []detection.Finding{high}}}}
+	new := Report{Commits: []CommitResult{{Hash: "aaa", Findings: []detection.Finding{low}}}}
+
+	d := Diff(old, new)
+	if d.Regressions != 1 || len(d.Commits) != 1 {
+		t.Fatalf("diff = %+v, want one regression", d)
+	}
+	cd := d.Commits[0]
+	if cd.Change != ChangeChanged || len(cd.Changed) != 1 || cd.Changed[0].Old.Confidence != detection.ConfidenceHigh {
+		t.Errorf("commit = %+v", cd)
+	}
+
+	// The other way round is an upgrade.
+	if d := Diff(new, old); d.Regressions != 0 || len(d.Commits) != 1 {
+		t.Errorf("upgrade diff = %+v, want one commit and no regressions", d)
+	}
+}
+
+func TestLoadReport(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	report, err := LoadReport(write("v1.json", `{"schema_version": 1, "commits": [{"hash": "aaa", "findings": null}], "summary": {"total_commits": 1}}`))
+	if err != nil || len(report.Commits) != 1 || report.Summary.TotalCommits != 1 {
+		t.Errorf("LoadReport = %+v, %v", report, err)
+	}
+	// Reports from before the schema version was added still load.
+	if _, err := LoadReport(write("v0.json", `{"commits": [], "summary": {}}`)); err != nil {
+		t.Errorf("unversioned report: %v", err)
+	}
+	if _, err := LoadReport(write("v2.json", `{"schema_version": 2, "commits": []}`)); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("newer schema version: err = %v", err)
+	}
+	if _, err := LoadReport(write("bad.json", `not json`)); err == nil || !strings.Contains(err.Error(), "bad.json") {
+		t.Errorf("invalid JSON: err = %v", err)
+	}
+}
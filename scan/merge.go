@@ -0,0 +1,101 @@
+package scan
+
+import (
+	"slices"
+
+	"github.com/chaoss/ai-detection-action/detection"
+)
+
+// Merge combines reports of separate scans, e.g. of shards of a history
+// scanned on different machines, into one. Commits are deduplicated by hash
+// and kept in the order they are first seen; a commit in several reports
+// gets the union of their findings, and a recorded finding that another
+// report found again is no longer listed as recorded. The summary is
+// recomputed from the merged commits.
+//
+// Sources holds the Metadata of each report that has one, then the Sources
+// of each report that was itself merged, so merging merged reports keeps
+// every scan's metadata.
+func Merge(reports ...Report) Report {
+	commits := []CommitResult{}
+	index := map[string]int{}
+	var sources []Metadata
+	for _, r := range reports {
+		if r.Metadata != nil {
+			sources = append(sources, *r.Metadata)
+		}
+		sources = append(sources, r.Sources...)
+
+		for _, cr := range r.Commits {
+			i, ok := index[cr.Hash]
+			if !ok {
+				index[cr.Hash] = len(commits)
+				commits = append(commits, cloneCommit(cr))
+				continue
+			}
+			mergeCommit(&commits[i], cr)
+		}
+	}
+
+	for i := range commits {
+		cr := &commits[i]
+		cr.Recorded = slices.DeleteFunc(cr.Recorded, func(f detection.Finding) bool {
+			return containsFinding(cr.Findings, f)
+		})
+		if len(cr.Recorded) == 0 {
+			cr.Recorded = nil
+		}
+	}
+
+	report := buildReport(commits)
+	report.Sources = sources
+	return report
+}
+
+// cloneCommit copies cr so that merging into it leaves the report it came
+// from untouched.
+func cloneCommit(cr CommitResult) CommitResult {
+	cr.Findings = slices.Clone(cr.Findings)
+	cr.Recorded = slices.Clone(cr.Recorded)
+	return cr
+}
+
+// mergeCommit adds the findings of other that dst lacks, and fills in what
+// dst does not know about the commit.
+func mergeCommit(dst *CommitResult, other CommitResult) {
+	if dst.Subject == "" {
+		dst.Subject = other.Subject
+	}
+	if dst.Author == nil {
+		dst.Author = other.Author
+	}
+	if dst.Committer == nil {
+		dst.Committer = other.Committer
+	}
+	for _, f := range other.Findings {
+		if !containsFinding(dst.Findings, f) {
+			dst.Findings = append(dst.Findings, f)
+		}
+	}
+	for _, f := range other.Recorded {
+		if !containsFinding(dst.Recorded, f) {
+			dst.Recorded = append(dst.Recorded, f)
+		}
+	}
+}
+
+func containsFinding(findings []detection.Finding, f detection.Finding) bool {
+	return slices.ContainsFunc(findings, func(g detection.Finding) bool {
+		return sameFinding(f, g)
+	})
+}
+
+// sameFinding reports whether a and b are equal in every field.
+func sameFinding(a, b detection.Finding) bool {
+	return a.Detector == b.Detector && a.Tool == b.Tool && a.ToolID == b.ToolID &&
+		a.Confidence == b.Confidence && a.Detail == b.Detail && a.Role == b.Role &&
+		a.Model == b.Model && a.SelfDisclosed == b.SelfDisclosed && a.Kind == b.Kind &&
+		slices.EqualFunc(a.Lines, b.Lines, func(x, y detection.FileLines) bool {
+			return x.Path == y.Path && slices.Equal(x.Ranges, y.Ranges)
+		})
+}
@@ -0,0 +1,85 @@
+package scan
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/chaoss/ai-detection-action/detection"
+)
+
+func TestMerge(t *testing.T) {
+	coauthor := detection.Finding{Detector: "coauthor", Tool: "Claude Code", Confidence: detection.ConfidenceHigh, Detail: "trailer"}
+	mention := detection.Finding{Detector: "toolmention", Tool: "Claude", Confidence: detection.ConfidenceLow, Detail: "mention"}
+	gitai := detection.Finding{Detector: "gitai", Tool: "Cursor", Confidence: detection.ConfidenceHigh, Detail: "log",
+		Lines: []detection.FileLines{{Path: "a.go", Ranges: []detection.LineRange{{Start: 1, End: 3}}}}}
+	author := &Signature{Name: "Ada", Email: "ada@example.com", Date: "2024-05-01T09:30:00Z"}
+
+	first := []CommitResult{
+		{Hash: "aaa", Findings: []detection.Finding{coauthor}},
+		{Hash: "bbb", Subject: "docs"},
+		{Hash: "ccc", Recorded: []detection.Finding{gitai}},
+	}
+	second := []CommitResult{
+		{Hash: "ccc", Findings: []detection.Finding{gitai}},
+		{Hash: "aaa", Subject: "feature", Author: author, Findings: []detection.Finding{coauthor, mention}},
+		{Hash: "ddd", Findings: []detection.Finding{mention}},
+	}
+	metaA := Metadata{ToolVersion: "1.0.0", Range: Range{Spec: "v1..v2"}}
+	metaB := Metadata{ToolVersion: "1.0.0", Range: Range{Spec: "v2..v3"}}
+	a := Report{Commits: first, Summary: Summarize(first), Metadata: &metaA}
+	b := Report{Commits: second, Summary: Summarize(second), Metadata: &metaB}
+
+	merged := Merge(a, b)
+
+	var hashes []string
+	for _, cr := range merged.Commits {
+		hashes = append(hashes, cr.Hash)
+	}
+	if want := []string{"aaa", "bbb", "ccc", "ddd"}; !reflect.DeepEqual(hashes, want) {
+		t.Fatalf("commits = %v, want %v", hashes, want)
+	}
+
+	aaa := merged.Commits[0]
+	if !reflect.DeepEqual(aaa.Findings, []detection.Finding{coauthor, mention}) {
+		t.Errorf("aaa findings = %+v, want the union without duplicates", aaa.Findings)
+	}
+	if aaa.Subject != "feature" || aaa.Author != author {
+		t.Errorf("aaa = %+v, want subject and author filled in from the second report", aaa)
+	}
+	ccc := merged.Commits[2]
+	if len(ccc.Findings) != 1 || ccc.Recorded != nil {
+		t.Errorf("ccc = %+v, want the recorded finding found again and no longer recorded", ccc)
+	}
+
+	if !reflect.DeepEqual(merged.Summary, Summarize(merged.Commits)) {
+		t.Errorf("summary = %+v, want it recomputed", merged.Summary)
+	}
+	if merged.Summary.TotalCommits != 4 || merged.Summary.AICommits != 3 || merged.Summary.ToolCounts["Claude"] != 2 {
+		t.Errorf("summary = %+v", merged.Summary)
+	}
+	if merged.Metadata != nil || len(merged.Sources) != 2 || merged.Sources[1].Range.Spec != "v2..v3" {
+		t.Errorf("metadata = %v, sources = %+v", merged.Metadata, merged.Sources)
+	}
+
+	// The inputs are left as they were.
+	if len(a.Commits[0].Findings) != 1 || len(a.Commits[2].Recorded) != 1 {
+		t.Errorf("Merge changed its input: %+v", a.Commits)
+	}
+}
+
+func TestMergeMerged(t *testing.T) {
+	meta := func(spec string) *Metadata { return &Metadata{Range: Range{Spec: spec}} }
+	ab := Merge(Report{Metadata: meta("a")}, Report{Metadata: meta("b")}, Report{})
+	abc := Merge(ab, Report{Metadata: meta("c")})
+
+	var specs []string
+	for _, m := range abc.Sources {
+		specs = append(specs, m.Range.Spec)
+	}
+	if want := []string{"a", "b", "c"}; !reflect.DeepEqual(specs, want) {
+		t.Errorf("sources = %v, want %v", specs, want)
+	}
+	if abc.Commits == nil || len(abc.Commits) != 0 {
+		t.Errorf("commits = %#v, want an empty list", abc.Commits)
+	}
+}
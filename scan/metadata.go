@@ -0,0 +1,93 @@
+package scan
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/detection/catalog"
+	"github.com/chaoss/ai-detection-action/gitops"
+)
+
+// ReportSchemaVersion is the version of the JSON report format, which
+// schema/report.schema.json describes. It is bumped when a change would
+// break existing readers; fields may be added without it.
+const ReportSchemaVersion = 1
+
+// Metadata records what produced a report, so that a saved report can be
+// compared with later ones: the program, the repository and range scanned,
+// the detectors and the filters applied.
+type Metadata struct {
+	ToolVersion string     `json:"tool_version"`
+	Repository  Repository `json:"repository"`
+	Range       Range      `json:"range"`
+	Detectors   Detectors  `json:"detectors"`
+	Filters     Filters    `json:"filters"`
+	StartedAt   time.Time  `json:"started_at"`
+	FinishedAt  time.Time  `json:"finished_at"`
+}
+
+// Repository identifies the scanned repository.
+type Repository struct {
+	Path      string `json:"path"`                 // Absolute path
+	RemoteURL string `json:"remote_url,omitempty"` // URL of origin, without credentials
+}
+
+// Range is the commit range scanned, as given and as resolved when the scan
+// started.
+type Range struct {
+	Spec string `json:"spec"`
+	gitops.ResolvedRange
+}
+
+// Detectors describes the detector set: the detectors in the order they ran,
+// the built-in detectors' detection.SignatureVersion, the tool catalog's
+// fingerprint and the Fingerprint of the whole set, which also covers any
+// rules file.
+type Detectors struct {
+	Names            []string `json:"names"`
+	SignatureVersion string   `json:"signature_version"`
+	Catalog          string   `json:"catalog"`
+	Fingerprint      string   `json:"fingerprint"`
+}
+
+// Filters records the filters applied to the findings.
+type Filters struct {
+	MinConfidence string `json:"min_confidence"`
+}
+
+// NewMetadata returns the metadata of a scan of commitRange in the repository
+// at repoPath with detectors, starting now. The caller fills in ToolVersion,
+// Filters and, once the scan is done, FinishedAt.
+func NewMetadata(repoPath, commitRange string, detectors []detection.Detector) (Metadata, error) {
+	started := time.Now().UTC()
+
+	path, err := filepath.Abs(repoPath)
+	if err != nil {
+		return Metadata{}, err
+	}
+	remote, err := gitops.RemoteURL(repoPath, "origin")
+	if err != nil {
+		return Metadata{}, err
+	}
+	resolved, err := gitops.ResolveRange(repoPath, commitRange)
+	if err != nil {
+		return Metadata{}, err
+	}
+
+	names := make([]string, len(detectors))
+	for i, d := range detectors {
+		names[i] = d.Name()
+	}
+	return Metadata{
+		Repository: Repository{Path: path, RemoteURL: remote},
+		Range:      Range{Spec: commitRange, ResolvedRange: resolved},
+		Detectors: Detectors{
+			Names:            names,
+			SignatureVersion: detection.SignatureVersion,
+			Catalog:          catalog.Fingerprint(),
+			Fingerprint:      Fingerprint(detectors),
+		},
+		StartedAt: started,
+	}, nil
+}
@@ -0,0 +1,91 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/chaoss/ai-detection-action/detection"
+)
+
+// DefaultNotesRef is where scan results are stored as git notes unless
+// another ref is named.
+const DefaultNotesRef = "refs/notes/ai-detection"
+
+// Note is the JSON document stored in a git note for one scanned commit.
+// Commit and Fingerprint record what produced it: a note copied to a
+// rewritten commit (git notes copy, or notes.rewriteRef during a rebase)
+// names the original commit, and a note written by a different detector set
+// has a different fingerprint. Either way its findings are shown as recorded
+// ones rather than reused as the commit's result. Recorded keeps the
+// recorded findings of the result the note was written from, so that
+// rewriting a note does not lose them.
+type Note struct {
+	Commit      string              `json:"commit"`
+	Fingerprint string              `json:"fingerprint"`
+	Subject     string              `json:"subject,omitempty"`
+	Author      *Signature          `json:"author,omitempty"`
+	Committer   *Signature          `json:"committer,omitempty"`
+	Findings    []detection.Finding `json:"findings"`
+	Recorded    []detection.Finding `json:"recorded,omitempty"`
+}
+
+// NewNote returns the note recording r as scanned by detectors. Findings of
+// volatile detectors are not recorded.
+func NewNote(r CommitResult, detectors []detection.Detector) Note {
+	r = withoutVolatile(r, detectors)
+	return Note{
+		Commit:      r.Hash,
+		Fingerprint: Fingerprint(detectors),
+		Subject:     r.Subject,
+		Author:      r.Author,
+		Committer:   r.Committer,
+		Findings:    r.Findings,
+		Recorded:    r.Recorded,
+	}
+}
+
+// Marshal encodes the note for storage.
+func (n Note) Marshal() ([]byte, error) {
+	data, err := json.MarshalIndent(n, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// ParseNote decodes a note written by NewNote and Marshal.
+func ParseNote(data []byte) (Note, error) {
+	var n Note
+	if err := json.Unmarshal(data, &n); err != nil {
+		return Note{}, fmt.Errorf("parsing note: %w", err)
+	}
+	if n.Commit == "" {
+		return Note{}, fmt.Errorf("parsing note: no commit hash")
+	}
+	return n, nil
+}
+
+// withRecorded attaches the findings of a note that could not be reused for
+// r, and those it recorded in turn, as r's Recorded findings, leaving out any
+// the scan found again and listing each finding once.
+func withRecorded(r CommitResult, note *Note) CommitResult {
+	if note == nil {
+		return r
+	}
+	type key struct{ detector, toolID, tool, detail string }
+	found := map[key]bool{}
+	for _, f := range r.Findings {
+		found[key{f.Detector, f.ToolID, f.Tool, f.Detail}] = true
+	}
+	for _, findings := range [][]detection.Finding{note.Findings, note.Recorded} {
+		for _, f := range findings {
+			k := key{f.Detector, f.ToolID, f.Tool, f.Detail}
+			if found[k] {
+				continue
+			}
+			found[k] = true
+			r.Recorded = append(r.Recorded, f)
+		}
+	}
+	return r
+}
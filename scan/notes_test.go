@@ -0,0 +1,151 @@
+package scan
+
+import (
+	"testing"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/gitops"
+)
+
+func TestNoteRoundTrip(t *testing.T) {
+	r := CommitResult{
+		Hash:     "0123456789abcdef0123456789abcdef01234567",
+		Findings: []detection.Finding{{Detector: "coauthor", Tool: "Claude Code", ToolID: "claude-code", Confidence: detection.ConfidenceHigh, Detail: "Co-Authored-By trailer"}},
+	}
+	data, err := NewNote(r, allDetectors()).Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	note, err := ParseNote(data)
+	if err != nil {
+		t.Fatalf("ParseNote: %v", err)
+	}
+	if note.Commit != r.Hash || note.Fingerprint != Fingerprint(allDetectors()) || len(note.Findings) != 1 {
+		t.Errorf("ParseNote = %+v", note)
+	}
+
+	// Volatile findings are left out of the note.
+	r.Findings = append(r.Findings, detection.Finding{Detector: "volatile", Tool: "Acme"})
+	if note := NewNote(r, append(allDetectors(), &volatileDetector{})); len(note.Findings) != 1 || len(r.Findings) != 2 {
+		t.Errorf("note findings = %+v, want only the coauthor finding", note.Findings)
+	}
+
+	for _, bad := range []string{"not json", "{}"} {
+		if _, err := ParseNote([]byte(bad)); err == nil {
+			t.Errorf("ParseNote(%q): expected error", bad)
+		}
+	}
+}
+
+func TestScannerReadsNotes(t *testing.T) {
+	dir, hashes := initTestRepo(t)
+
+	calls := &callsDetector{}
+	detectors := append(allDetectors(), calls)
+	report, err := (&Scanner{Detectors: detectors}).ScanCommitRange(dir, "")
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+
+	// Record every result, then pretend the aider commit was rewritten
+	// without its message: its note now describes another commit.
+	notes := map[string][]byte{}
+	for _, r := range report.Commits {
+		note := NewNote(r, detectors)
+		if r.Hash == hashes[2] {
+			note.Commit = hashes[0]
+		}
+		if notes[r.Hash], err = note.Marshal(); err != nil {
+			t.Fatalf("Marshal: %v", err)
+		}
+	}
+	if err := gitops.WriteNotes(dir, DefaultNotesRef, notes, "scan\n"); err != nil {
+		t.Fatalf("WriteNotes: %v", err)
+	}
+
+	for _, jobs := range []int{1, 4} {
+		calls.calls.Store(0)
+		got, err := (&Scanner{Detectors: detectors, Jobs: jobs, NotesRef: DefaultNotesRef}).ScanCommitRange(dir, "")
+		if err != nil {
+			t.Fatalf("jobs=%d: scan: %v", jobs, err)
+		}
+		if n := calls.calls.Load(); n != 1 {
+			t.Errorf("jobs=%d: detectors ran on %d commits, want only the rewritten one", jobs, n)
+		}
+
+		for i, r := range got.Commits {
+			want := report.Commits[i]
+			if r.Hash != want.Hash || len(r.Findings) != len(want.Findings) {
+				t.Errorf("jobs=%d: commit %d = %s with %d findings, want %s with %d", jobs, i, r.Hash, len(r.Findings), want.Hash, len(want.Findings))
+			}
+			if r.Subject != want.Subject {
+				t.Errorf("jobs=%d: commit %d subject = %q, want %q", jobs, i, r.Subject, want.Subject)
+			}
+			// The rewritten commit still has its aider message here, so
+			// nothing is left over to record.
+			if len(r.Recorded) != 0 {
+				t.Errorf("jobs=%d: commit %s has %d recorded findings, want 0", jobs, r.Hash[:7], len(r.Recorded))
+			}
+		}
+	}
+
+	// A note whose findings the commit no longer shows brings them back as
+	// recorded findings, and they count in the summary.
+	lost := NewNote(report.Commits[0], detectors) // aider commit, newest first
+	lost.Commit = hashes[1]
+	data, err := lost.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := gitops.WriteNotes(dir, DefaultNotesRef, map[string][]byte{hashes[0]: data}, "copy\n"); err != nil {
+		t.Fatalf("WriteNotes: %v", err)
+	}
+	got, err := (&Scanner{Detectors: detectors, NotesRef: DefaultNotesRef}).ScanCommitRange(dir, "")
+	if err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	initial := got.Commits[len(got.Commits)-1]
+	if initial.Hash != hashes[0] || len(initial.Findings) != 0 || len(initial.Recorded) == 0 {
+		t.Fatalf("initial commit = %+v, want only recorded findings", initial)
+	}
+	if got.Summary.AICommits != 3 {
+		t.Errorf("ai_commits = %d, want 3 with the recorded findings", got.Summary.AICommits)
+	}
+
+	// Rewriting the note from that result keeps the recorded findings.
+	if data, err = NewNote(initial, detectors).Marshal(); err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	if err := gitops.WriteNotes(dir, DefaultNotesRef, map[string][]byte{hashes[0]: data}, "rescan\n"); err != nil {
+		t.Fatalf("WriteNotes: %v", err)
+	}
+	calls.calls.Store(0)
+	if got, err = (&Scanner{Detectors: detectors, NotesRef: DefaultNotesRef}).ScanCommitRange(dir, ""); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if n := calls.calls.Load(); n != 1 {
+		t.Errorf("detectors ran on %d commits, want only the rewritten one", n)
+	}
+	if again := got.Commits[len(got.Commits)-1]; len(again.Recorded) != len(initial.Recorded) {
+		t.Errorf("initial commit = %+v, want its %d recorded findings kept", again, len(initial.Recorded))
+	}
+}
+
+func TestWithRecordedListsEachFindingOnce(t *testing.T) {
+	claude := detection.Finding{Detector: "coauthor", Tool: "Claude Code", ToolID: "claude-code", Confidence: detection.ConfidenceHigh, Detail: "Co-Authored-By trailer"}
+	aider := detection.Finding{Detector: "message", Tool: "Aider", ToolID: "aider", Confidence: detection.ConfidenceMedium, Detail: "pattern"}
+	cursor := detection.Finding{Detector: "coauthor", Tool: "Cursor", ToolID: "cursor", Confidence: detection.ConfidenceHigh, Detail: "Co-Authored-By trailer"}
+
+	// Spare capacity in the note's findings must not be written into.
+	findings := make([]detection.Finding, 2, 4)
+	findings[0], findings[1] = claude, aider
+	note := &Note{Findings: findings, Recorded: []detection.Finding{aider, cursor, cursor}}
+
+	r := withRecorded(CommitResult{Findings: []detection.Finding{claude}}, note)
+	if len(r.Recorded) != 2 || r.Recorded[0].Tool != "Aider" || r.Recorded[1].Tool != "Cursor" {
+		t.Errorf("recorded = %+v, want Aider and Cursor once each", r.Recorded)
+	}
+	if extra := findings[:3][2]; extra.Tool != "" {
+		t.Errorf("note findings' spare capacity was written: %+v", extra)
+	}
+}
@@ -1,44 +1,70 @@
 package scan
 
 import (
+	"iter"
+	"strings"
+	"time"
+
 	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/detection/trailers"
 	"github.com/chaoss/ai-detection-action/gitops"
 )
 
-// CommitResult holds findings for a single commit.
+// CommitResult holds findings for a single commit. Recorded holds findings a
+// previous scan stored in a git note for the commit that this scan did not
+// find again, e.g. because a squash or rebase dropped the trailers they came
+// from.
 type CommitResult struct {
-	Hash     string              `json:"hash"`
-	Findings []detection.Finding `json:"findings"`
+	Hash      string              `json:"hash"`
+	Subject   string              `json:"subject,omitempty"` // First line of the commit message
+	Author    *Signature          `json:"author,omitempty"`
+	Committer *Signature          `json:"committer,omitempty"`
+	Findings  []detection.Finding `json:"findings"`
+	Recorded  []detection.Finding `json:"recorded,omitempty"`
+}
+
+// Signature identifies the author or committer of a commit. Date is when
+// they authored or committed it, in RFC 3339 format with their UTC offset.
+type Signature struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Date  string `json:"date"`
 }
 
-// Summary aggregates stats across all commits scanned.
+// Summary aggregates stats across all commits scanned. Negative disclosures
+// are counted separately and never contribute to the AI counts.
 type Summary struct {
-	TotalCommits int            `json:"total_commits"`
-	AICommits    int            `json:"ai_commits"`
-	ToolCounts   map[string]int `json:"tool_counts"`
-	ByConfidence map[string]int `json:"by_confidence"`
+	TotalCommits        int            `json:"total_commits"`
+	AICommits           int            `json:"ai_commits"`
+	ToolCounts          map[string]int `json:"tool_counts"`
+	ByConfidence        map[string]int `json:"by_confidence"`
+	NegativeDisclosures int            `json:"negative_disclosures,omitempty"`
 }
 
-// Report holds the full scan results.
+// Report holds the full scan results. SchemaVersion is ReportSchemaVersion
+// in a report that was written out, and Metadata says what produced it, if
+// known. A report that Merge combined from others has no Metadata of its own;
+// Sources holds theirs instead.
 type Report struct {
-	Commits []CommitResult `json:"commits"`
-	Summary Summary        `json:"summary"`
+	SchemaVersion int            `json:"schema_version,omitempty"`
+	Commits       []CommitResult `json:"commits"`
+	Summary       Summary        `json:"summary"`
+	Metadata      *Metadata      `json:"metadata,omitempty"`
+	Sources       []Metadata     `json:"sources,omitempty"`
 }
 
-// ScanCommitRange scans all commits in the given range using the provided detectors.
+// ScanCommitRange scans all commits in the given range using the provided
+// detectors. See gitops.ListCommits for the range syntax.
 func ScanCommitRange(repoPath, commitRange string, detectors []detection.Detector) (Report, error) {
-	commits, err := gitops.ListCommits(repoPath, commitRange)
-	if err != nil {
-		return Report{}, err
-	}
-
-	var results []CommitResult
-	for _, c := range commits {
-		result := scanOneCommit(c, detectors)
-		results = append(results, result)
-	}
+	return (&Scanner{Detectors: detectors}).ScanCommitRange(repoPath, commitRange)
+}
 
-	return buildReport(results), nil
+// Stream scans the commits in the given range one at a time, yielding each
+// result as soon as its detectors have run, so that callers can report
+// progress and keep memory flat on large histories. An error is yielded once,
+// with a zero CommitResult, and ends the scan.
+func Stream(repoPath, commitRange string, detectors []detection.Detector) iter.Seq2[CommitResult, error] {
+	return (&Scanner{Detectors: detectors}).Stream(repoPath, commitRange)
 }
 
 // ScanCommit scans a single commit by hash.
@@ -48,7 +74,7 @@ func ScanCommit(repoPath, hash string, detectors []detection.Detector) (CommitRe
 		return CommitResult{}, err
 	}
 
-	return scanOneCommit(c, detectors), nil
+	return scanOneCommit(repoPath, c, detectors), nil
 }
 
 // ScanText runs detectors against arbitrary text (PR body, comments, etc).
@@ -61,43 +87,117 @@ func ScanText(text string, detectors []detection.Detector) []detection.Finding {
 	return findings
 }
 
-func scanOneCommit(c gitops.Commit, detectors []detection.Detector) CommitResult {
+func scanOneCommit(repoPath string, c gitops.Commit, detectors []detection.Detector) CommitResult {
+	// A message without trailers gets an empty, non-nil block, so that
+	// detectors calling MessageTrailers do not parse it again.
+	parsed := trailers.Parse(c.Message)
+	if parsed == nil {
+		parsed = trailers.Trailers{}
+	}
 	input := detection.Input{
 		CommitHash:    c.Hash,
 		CommitEmail:   c.CommitterEmail,
+		CommitterName: c.CommitterName,
+		AuthorEmail:   c.AuthorEmail,
+		AuthorName:    c.AuthorName,
 		CommitMessage: c.Message,
+		Trailers:      parsed,
+		Files:         fileChanges(c.Files),
+		AuthorshipLog: c.AuthorshipLog,
+		RepoPath:      repoPath,
 	}
 
+	// Volatile detectors run after the rest, as they do on a result taken
+	// from the cache or a note, so that both list findings in one order.
 	var findings []detection.Finding
-	for _, d := range detectors {
-		findings = append(findings, d.Detect(input)...)
+	for _, volatile := range []bool{false, true} {
+		for _, d := range detectors {
+			if detection.IsVolatile(d) == volatile {
+				findings = append(findings, d.Detect(input)...)
+			}
+		}
 	}
 
 	return CommitResult{
-		Hash:     c.Hash,
-		Findings: findings,
+		Hash:      c.Hash,
+		Subject:   subject(c.Message),
+		Author:    &Signature{Name: c.AuthorName, Email: c.AuthorEmail, Date: c.AuthorTime.Format(time.RFC3339)},
+		Committer: &Signature{Name: c.CommitterName, Email: c.CommitterEmail, Date: c.CommitTime.Format(time.RFC3339)},
+		Findings:  findings,
 	}
 }
 
+// subject returns the first line of a commit message.
+func subject(message string) string {
+	line, _, _ := strings.Cut(strings.TrimLeft(message, "\n"), "\n")
+	return strings.TrimSpace(line)
+}
+
+func fileChanges(files []gitops.FileChange) []detection.FileChange {
+	if files == nil {
+		return nil
+	}
+	out := make([]detection.FileChange, len(files))
+	for i, f := range files {
+		out[i] = detection.FileChange{Path: f.Path, Action: f.Action}
+	}
+	return out
+}
+
 func buildReport(results []CommitResult) Report {
-	summary := Summary{
-		TotalCommits: len(results),
+	return Report{
+		Commits: results,
+		Summary: Summarize(results),
+	}
+}
+
+// NewSummary returns an empty summary, ready for Add.
+func NewSummary() Summary {
+	return Summary{
 		ToolCounts:   map[string]int{},
 		ByConfidence: map[string]int{},
 	}
+}
 
+// Summarize computes the summary for a set of commit results.
+func Summarize(results []CommitResult) Summary {
+	summary := NewSummary()
 	for _, r := range results {
-		if len(r.Findings) > 0 {
-			summary.AICommits++
-		}
-		for _, f := range r.Findings {
-			summary.ToolCounts[f.Tool]++
-			summary.ByConfidence[f.Confidence.String()]++
-		}
+		summary.Add(r)
 	}
+	return summary
+}
 
-	return Report{
-		Commits: results,
-		Summary: summary,
+// Add counts one more commit result into the summary, so that a summary can
+// be built while results are streamed. Recorded findings count the same as
+// the commit's own.
+func (s *Summary) Add(r CommitResult) {
+	if s.ToolCounts == nil {
+		s.ToolCounts = map[string]int{}
+	}
+	if s.ByConfidence == nil {
+		s.ByConfidence = map[string]int{}
+	}
+
+	findings := r.Findings
+	if len(r.Recorded) > 0 {
+		findings = append(append([]detection.Finding(nil), r.Findings...), r.Recorded...)
+	}
+
+	s.TotalCommits++
+	if detection.AnyIndicatesAI(findings) {
+		s.AICommits++
+	}
+	negative := false
+	for _, f := range findings {
+		if !f.IndicatesAI() {
+			negative = true
+			continue
+		}
+		s.ToolCounts[f.Tool]++
+		s.ByConfidence[f.Confidence.String()]++
+	}
+	if negative {
+		s.NegativeDisclosures++
 	}
 }
@@ -132,6 +132,9 @@ func TestScanCommit(t *testing.T) {
 	if result.Hash != hashes[1] {
 		t.Errorf("hash = %q, want %q", result.Hash, hashes[1])
 	}
+	if result.Subject != "fix: update handler" {
+		t.Errorf("subject = %q, want the first line of the message", result.Subject)
+	}
 
 	if len(result.Findings) == 0 {
 		t.Error("expected findings for co-author commit")
@@ -148,6 +151,36 @@ func TestScanCommit(t *testing.T) {
 	}
 }
 
+// inputDetector records the inputs it is given.
+type inputDetector struct {
+	inputs []detection.Input
+}
+
+func (d *inputDetector) Name() string { return "input" }
+
+func (d *inputDetector) Detect(input detection.Input) []detection.Finding {
+	d.inputs = append(d.inputs, input)
+	return nil
+}
+
+func TestScanCommitParsesTrailersOnce(t *testing.T) {
+	dir, hashes := initTestRepo(t)
+	d := &inputDetector{}
+
+	for _, hash := range hashes[:2] {
+		if _, err := ScanCommit(dir, hash, []detection.Detector{d}); err != nil {
+			t.Fatalf("ScanCommit: %v", err)
+		}
+	}
+	// The initial commit has no trailers, but is marked as parsed.
+	if tr := d.inputs[0].Trailers; tr == nil || len(tr) != 0 {
+		t.Errorf("trailers of a message without any = %#v, want empty and non-nil", tr)
+	}
+	if tr := d.inputs[1].Trailers; len(tr) != 1 || tr[0].Key != "Co-Authored-By" {
+		t.Errorf("trailers = %+v, want the Co-Authored-By trailer", tr)
+	}
+}
+
 func TestScanText(t *testing.T) {
 	detectors := allDetectors()
 
@@ -195,3 +228,164 @@ func TestReportSummaryByConfidence(t *testing.T) {
 		t.Error("expected medium confidence findings")
 	}
 }
+
+func TestScanCommitAuthorBot(t *testing.T) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "file.txt"), []byte("x"), 0644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := wt.Add("file.txt"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+
+	// Squash-merged through the GitHub web UI: the agent is the author and
+	// GitHub's web-flow identity is the committer.
+	hash, err := wt.Commit("Fix flaky test", &git.CommitOptions{
+		Author: &object.Signature{
+			Name:  "Copilot",
+			Email: "198982749+Copilot@users.noreply.github.com",
+			When:  time.Now(),
+		},
+		Committer: &object.Signature{
+			Name:  "GitHub",
+			Email: "noreply@github.com",
+			When:  time.Now(),
+		},
+	})
+	if err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	result, err := ScanCommit(dir, hash.String(), allDetectors())
+	if err != nil {
+		t.Fatalf("ScanCommit: %v", err)
+	}
+
+	found := false
+	for _, f := range result.Findings {
+		if f.Detector == "committer" && f.Role == detection.RoleAuthor {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected committer finding with author role, got %+v", result.Findings)
+	}
+}
+
+func TestSummarizeNegativeDisclosure(t *testing.T) {
+	results := []CommitResult{
+		{
+			Hash: "aaa",
+			Findings: []detection.Finding{
+				{Detector: "disclosure", Confidence: detection.ConfidenceHigh, Kind: detection.KindNegativeDisclosure},
+			},
+		},
+		{
+			Hash: "bbb",
+			Findings: []detection.Finding{
+				{Detector: "disclosure", Tool: "Claude Code", Confidence: detection.ConfidenceHigh, SelfDisclosed: true},
+			},
+		},
+		{Hash: "ccc"},
+	}
+
+	summary := Summarize(results)
+	if summary.TotalCommits != 3 {
+		t.Errorf("total commits = %d, want 3", summary.TotalCommits)
+	}
+	if summary.AICommits != 1 {
+		t.Errorf("ai commits = %d, want 1", summary.AICommits)
+	}
+	if summary.NegativeDisclosures != 1 {
+		t.Errorf("negative disclosures = %d, want 1", summary.NegativeDisclosures)
+	}
+	if summary.ByConfidence["high"] != 1 {
+		t.Errorf("high = %d, want 1 (negative disclosures must not count)", summary.ByConfidence["high"])
+	}
+	if _, ok := summary.ToolCounts[""]; ok {
+		t.Error("negative disclosure should not appear in tool counts")
+	}
+}
+
+func TestStream(t *testing.T) {
+	dir, hashes := initTestRepo(t)
+	detectors := allDetectors()
+
+	summary := NewSummary()
+	var got []string
+	for result, err := range Stream(dir, "", detectors) {
+		if err != nil {
+			t.Fatalf("Stream: %v", err)
+		}
+		got = append(got, result.Hash)
+		summary.Add(result)
+	}
+
+	if len(got) != 3 || got[0] != hashes[2] || got[2] != hashes[0] {
+		t.Errorf("streamed %v, want all three commits newest first", got)
+	}
+
+	report, err := ScanCommitRange(dir, "", detectors)
+	if err != nil {
+		t.Fatalf("ScanCommitRange: %v", err)
+	}
+	if summary.TotalCommits != report.Summary.TotalCommits || summary.AICommits != report.Summary.AICommits {
+		t.Errorf("streamed summary %+v does not match report summary %+v", summary, report.Summary)
+	}
+}
+
+func TestStreamHashes(t *testing.T) {
+	dir, hashes := initTestRepo(t)
+
+	var got []CommitResult
+	for result, err := range (&Scanner{Detectors: allDetectors()}).StreamHashes(dir, []string{hashes[0], hashes[2]}) {
+		if err != nil {
+			t.Fatalf("StreamHashes: %v", err)
+		}
+		got = append(got, result)
+	}
+	if len(got) != 2 || got[0].Hash != hashes[0] || got[1].Hash != hashes[2] {
+		t.Fatalf("streamed %+v, want the two commits in the order given", got)
+	}
+	if len(got[0].Findings) != 0 || len(got[1].Findings) == 0 {
+		t.Errorf("findings = %v and %v, want only the aider commit's", got[0].Findings, got[1].Findings)
+	}
+
+	for _, err := range (&Scanner{Detectors: allDetectors()}).StreamHashes(dir, []string{"0123456789abcdef0123456789abcdef01234567"}) {
+		if err == nil {
+			t.Error("expected error for a missing commit")
+		}
+	}
+}
+
+func TestStreamStopsEarly(t *testing.T) {
+	dir, _ := initTestRepo(t)
+
+	n := 0
+	for _, err := range Stream(dir, "", allDetectors()) {
+		if err != nil {
+			t.Fatalf("Stream: %v", err)
+		}
+		n++
+		break
+	}
+	if n != 1 {
+		t.Errorf("got %d results, want 1", n)
+	}
+}
+
+func TestStreamError(t *testing.T) {
+	for _, err := range Stream(t.TempDir(), "", allDetectors()) {
+		if err == nil {
+			t.Error("expected error for non-repo directory")
+		}
+	}
+}
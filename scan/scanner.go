@@ -0,0 +1,390 @@
+package scan
+
+import (
+	"iter"
+	"sync"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/gitops"
+)
+
+// Scanner runs a set of detectors over commits, optionally on several
+// goroutines.
+type Scanner struct {
+	Detectors []detection.Detector
+
+	// Jobs is the number of commits scanned at once. With Jobs <= 1 every
+	// commit is scanned on the caller's goroutine.
+	Jobs int
+
+	// Cache, if set, supplies the results of commits scanned before, which
+	// are then neither diffed nor run through the detectors, and receives
+	// the results of the rest.
+	Cache *Cache
+
+	// NotesRef, if set, names the git notes ref an earlier scan stored its
+	// results under (see Note). A note written for the same commit by the
+	// same detectors is reused like a cache entry; the findings of any other
+	// note become the commit's Recorded findings.
+	NotesRef string
+}
+
+// ScanCommitRange scans all commits in the given range and returns the full
+// report.
+func (s *Scanner) ScanCommitRange(repoPath, commitRange string) (Report, error) {
+	var results []CommitResult
+	for result, err := range s.Stream(repoPath, commitRange) {
+		if err != nil {
+			return Report{}, err
+		}
+		results = append(results, result)
+	}
+
+	return buildReport(results), nil
+}
+
+// Stream scans the commits in the given range, yielding results in commit
+// order whatever the value of Jobs. An error is yielded once, with a zero
+// CommitResult, and ends the scan.
+//
+// With Jobs > 1 the scan is a pipeline: one goroutine reads and diffs
+// commits, Jobs goroutines run the detectors, and results are handed back in
+// order as they complete. Detectors that are not detection.ConcurrencySafe
+// are called by one goroutine at a time.
+func (s *Scanner) Stream(repoPath, commitRange string) iter.Seq2[CommitResult, error] {
+	return s.stream(repoPath, func(skip func(string) bool) iter.Seq2[gitops.Commit, error] {
+		return gitops.CommitsSkipping(repoPath, commitRange, skip)
+	})
+}
+
+// StreamHashes is Stream for the commits named by hashes, in that order,
+// rather than a range.
+func (s *Scanner) StreamHashes(repoPath string, hashes []string) iter.Seq2[CommitResult, error] {
+	return s.stream(repoPath, func(skip func(string) bool) iter.Seq2[gitops.Commit, error] {
+		return gitops.CommitsByHash(repoPath, hashes, skip)
+	})
+}
+
+// commitSource reads the commits to scan, yielding those skip returns true
+// for with only their hash, as gitops.CommitsSkipping does.
+type commitSource func(skip func(hash string) bool) iter.Seq2[gitops.Commit, error]
+
+func (s *Scanner) stream(repoPath string, source commitSource) iter.Seq2[CommitResult, error] {
+	if s.Jobs <= 1 {
+		return func(yield func(CommitResult, error) bool) {
+			volatile := &volatileInputs{repoPath: repoPath}
+			for item, err := range s.commits(repoPath, source) {
+				if err != nil {
+					yield(CommitResult{}, err)
+					return
+				}
+				result := item.cached
+				if item.hit {
+					var err error
+					if result, err = volatile.add(result, s.Detectors); err != nil {
+						yield(CommitResult{}, err)
+						return
+					}
+				} else {
+					result = scanOneCommit(repoPath, item.commit, s.Detectors)
+					if err := s.store(result); err != nil {
+						yield(CommitResult{}, err)
+						return
+					}
+				}
+				if !yield(withRecorded(result, item.note), nil) {
+					return
+				}
+			}
+		}
+	}
+
+	return func(yield func(CommitResult, error) bool) {
+		detectors := guardDetectors(s.Detectors)
+		volatile := &volatileInputs{repoPath: repoPath}
+
+		// Closing done when the caller stops early releases the reader; the
+		// workers then drain and exit once the reader closes jobs.
+		done := make(chan struct{})
+		defer close(done)
+
+		type job struct {
+			commit gitops.Commit
+			result chan CommitResult
+		}
+		type pending struct {
+			result chan CommitResult
+			fresh  bool // scanned now rather than taken from the cache
+			note   *Note
+			err    error
+		}
+
+		jobs := make(chan job)
+		// order holds results in commit order. Its capacity bounds how far
+		// reading may run ahead of the caller.
+		order := make(chan pending, 2*s.Jobs)
+
+		go func() {
+			defer close(order)
+			defer close(jobs)
+			for item, err := range s.commits(repoPath, source) {
+				if err != nil {
+					select {
+					case order <- pending{err: err}:
+					case <-done:
+					}
+					return
+				}
+				result := make(chan CommitResult, 1)
+				if item.hit {
+					result <- item.cached
+				}
+				select {
+				case order <- pending{result: result, fresh: !item.hit, note: item.note}:
+				case <-done:
+					return
+				}
+				if item.hit {
+					continue
+				}
+				select {
+				case jobs <- job{commit: item.commit, result: result}:
+				case <-done:
+					return
+				}
+			}
+		}()
+
+		for range s.Jobs {
+			go func() {
+				for j := range jobs {
+					j.result <- scanOneCommit(repoPath, j.commit, detectors)
+				}
+			}()
+		}
+
+		for p := range order {
+			if p.err != nil {
+				yield(CommitResult{}, p.err)
+				return
+			}
+			result := <-p.result
+			if p.fresh {
+				if err := s.store(result); err != nil {
+					yield(CommitResult{}, err)
+					return
+				}
+			} else {
+				var err error
+				if result, err = volatile.add(result, detectors); err != nil {
+					yield(CommitResult{}, err)
+					return
+				}
+			}
+			if !yield(withRecorded(result, p.note), nil) {
+				return
+			}
+		}
+	}
+}
+
+// scanItem is a commit to scan, or the earlier result that stands in for
+// it, with the note recorded for it that could not be reused, if any.
+type scanItem struct {
+	commit gitops.Commit
+	cached CommitResult
+	hit    bool
+	note   *Note
+}
+
+// commits yields the commits of source, with the earlier result of each
+// commit the cache or the notes have. Those commits are not diffed.
+func (s *Scanner) commits(repoPath string, source commitSource) iter.Seq2[scanItem, error] {
+	return func(yield func(scanItem, error) bool) {
+		if s.Cache == nil && s.NotesRef == "" {
+			for c, err := range source(nil) {
+				if !yield(scanItem{commit: c}, err) || err != nil {
+					return
+				}
+			}
+			return
+		}
+
+		var notes *gitops.Notes
+		if s.NotesRef != "" {
+			var err error
+			if notes, err = gitops.ReadNotes(repoPath, s.NotesRef); err != nil {
+				yield(scanItem{}, err)
+				return
+			}
+		}
+		fingerprint := Fingerprint(s.Detectors)
+
+		// skip runs just before each commit is yielded, so the last lookup
+		// always belongs to the commit that follows.
+		var last scanItem
+		var lastHash string
+		var lookupErr error
+		skip := func(hash string) bool {
+			last, lastHash = scanItem{}, hash
+			if notes != nil {
+				note, err := readNote(notes, hash)
+				if err != nil {
+					lookupErr = err
+					return true
+				}
+				if note != nil && note.Commit == hash && note.Fingerprint == fingerprint {
+					last.cached = CommitResult{
+						Hash:      hash,
+						Subject:   note.Subject,
+						Author:    note.Author,
+						Committer: note.Committer,
+						Findings:  note.Findings,
+						Recorded:  note.Recorded,
+					}
+					last.hit = true
+					return true
+				}
+				last.note = note
+			}
+			if s.Cache != nil {
+				last.cached, last.hit = s.Cache.Get(hash)
+			}
+			return last.hit
+		}
+		for c, err := range source(skip) {
+			if err == nil {
+				err = lookupErr
+			}
+			if err != nil {
+				yield(scanItem{}, err)
+				return
+			}
+			item := scanItem{commit: c}
+			if lastHash == c.Hash {
+				item.cached, item.hit, item.note = last.cached, last.hit, last.note
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
+
+// readNote returns the note for hash, or nil if it has none. Notes that are
+// not scan results are ignored.
+func readNote(notes *gitops.Notes, hash string) (*Note, error) {
+	data, ok, err := notes.Get(hash)
+	if err != nil || !ok {
+		return nil, err
+	}
+	note, err := ParseNote(data)
+	if err != nil {
+		return nil, nil
+	}
+	return &note, nil
+}
+
+// store saves a freshly scanned result in the cache, if there is one,
+// without the findings of volatile detectors.
+func (s *Scanner) store(result CommitResult) error {
+	if s.Cache == nil {
+		return nil
+	}
+	return s.Cache.Put(withoutVolatile(result, s.Detectors))
+}
+
+// volatileInputs adds to results taken from the cache or a note the findings
+// of the volatile detectors, which were left out when they were stored. The
+// authorship notes those detectors may need are read when first wanted.
+type volatileInputs struct {
+	repoPath   string
+	authorship *gitops.Notes
+}
+
+func (v *volatileInputs) add(r CommitResult, detectors []detection.Detector) (CommitResult, error) {
+	var input *detection.Input
+	for _, d := range detectors {
+		if !detection.IsVolatile(d) {
+			continue
+		}
+		if input == nil {
+			in, err := v.input(r.Hash)
+			if err != nil {
+				return CommitResult{}, err
+			}
+			input = &in
+		}
+		r.Findings = append(r.Findings, d.Detect(*input)...)
+	}
+	return r, nil
+}
+
+func (v *volatileInputs) input(hash string) (detection.Input, error) {
+	if v.authorship == nil {
+		notes, err := gitops.ReadNotes(v.repoPath, gitops.AuthorshipNotesRef)
+		if err != nil {
+			return detection.Input{}, err
+		}
+		v.authorship = notes
+	}
+	log, _, err := v.authorship.Get(hash)
+	if err != nil {
+		return detection.Input{}, err
+	}
+	return detection.Input{CommitHash: hash, RepoPath: v.repoPath, AuthorshipLog: log}, nil
+}
+
+// withoutVolatile returns r without the findings of volatile detectors.
+func withoutVolatile(r CommitResult, detectors []detection.Detector) CommitResult {
+	volatile := map[string]bool{}
+	for _, d := range detectors {
+		if detection.IsVolatile(d) {
+			volatile[d.Name()] = true
+		}
+	}
+	for i, f := range r.Findings {
+		if !volatile[f.Detector] {
+			continue
+		}
+		// Copy before filtering, so the caller's result keeps its findings.
+		kept := append([]detection.Finding(nil), r.Findings[:i]...)
+		for _, f := range r.Findings[i+1:] {
+			if !volatile[f.Detector] {
+				kept = append(kept, f)
+			}
+		}
+		r.Findings = kept
+		break
+	}
+	return r
+}
+
+// guardDetectors wraps every detector that is not safe for concurrent use so
+// that its Detect calls are serialized.
+func guardDetectors(detectors []detection.Detector) []detection.Detector {
+	out := make([]detection.Detector, len(detectors))
+	for i, d := range detectors {
+		if detection.IsConcurrencySafe(d) {
+			out[i] = d
+			continue
+		}
+		out[i] = &lockedDetector{Detector: d}
+	}
+	return out
+}
+
+type lockedDetector struct {
+	detection.Detector
+	mu sync.Mutex
+}
+
+// Volatile passes on whether the wrapped detector is volatile, so that
+// volatileInputs still runs it.
+func (l *lockedDetector) Volatile() bool { return detection.IsVolatile(l.Detector) }
+
+func (l *lockedDetector) Detect(input detection.Input) []detection.Finding {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.Detector.Detect(input)
+}
@@ -0,0 +1,161 @@
+package scan
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// initLongRepo creates a repository with n commits, every third of which has
+// an aider-style message.
+func initLongRepo(t *testing.T, n int) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	repo, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("init repo: %v", err)
+	}
+	wt, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("worktree: %v", err)
+	}
+
+	base := time.Now()
+	for i := range n {
+		name := fmt.Sprintf("file%d.txt", i)
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatalf("write file: %v", err)
+		}
+		if _, err := wt.Add(name); err != nil {
+			t.Fatalf("add: %v", err)
+		}
+		msg := fmt.Sprintf("commit %d", i)
+		if i%3 == 0 {
+			msg = "aider: " + msg
+		}
+		sig := &object.Signature{Name: "Test", Email: "human@example.com", When: base.Add(time.Duration(i) * time.Second)}
+		if _, err := wt.Commit(msg, &git.CommitOptions{Author: sig, Committer: sig}); err != nil {
+			t.Fatalf("commit: %v", err)
+		}
+	}
+	return dir
+}
+
+func TestScannerParallelMatchesSequential(t *testing.T) {
+	dir := initLongRepo(t, 40)
+
+	sequential, err := (&Scanner{Detectors: allDetectors()}).ScanCommitRange(dir, "")
+	if err != nil {
+		t.Fatalf("sequential scan: %v", err)
+	}
+
+	for _, jobs := range []int{2, 4, 16} {
+		parallel, err := (&Scanner{Detectors: allDetectors(), Jobs: jobs}).ScanCommitRange(dir, "")
+		if err != nil {
+			t.Fatalf("parallel scan (jobs=%d): %v", jobs, err)
+		}
+		if len(parallel.Commits) != len(sequential.Commits) {
+			t.Fatalf("jobs=%d: got %d commits, want %d", jobs, len(parallel.Commits), len(sequential.Commits))
+		}
+		for i := range sequential.Commits {
+			got, want := parallel.Commits[i], sequential.Commits[i]
+			if got.Hash != want.Hash || len(got.Findings) != len(want.Findings) {
+				t.Errorf("jobs=%d: commit %d = %s with %d findings, want %s with %d", jobs, i, got.Hash, len(got.Findings), want.Hash, len(want.Findings))
+			}
+		}
+		if parallel.Summary.AICommits != sequential.Summary.AICommits {
+			t.Errorf("jobs=%d: ai_commits = %d, want %d", jobs, parallel.Summary.AICommits, sequential.Summary.AICommits)
+		}
+	}
+}
+
+// countingDetector records the most Detect calls it has seen in flight.
+type countingDetector struct {
+	inFlight atomic.Int32
+	peak     atomic.Int32
+	safe     bool
+}
+
+func (d *countingDetector) Name() string          { return "counting" }
+func (d *countingDetector) ConcurrencySafe() bool { return d.safe }
+
+func (d *countingDetector) Detect(detection.Input) []detection.Finding {
+	n := d.inFlight.Add(1)
+	for {
+		peak := d.peak.Load()
+		if n <= peak || d.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(time.Millisecond)
+	d.inFlight.Add(-1)
+	return nil
+}
+
+func TestScannerSerializesUnsafeDetectors(t *testing.T) {
+	dir := initLongRepo(t, 20)
+
+	unsafe := &countingDetector{}
+	if _, err := (&Scanner{Detectors: []detection.Detector{unsafe}, Jobs: 8}).ScanCommitRange(dir, ""); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if peak := unsafe.peak.Load(); peak != 1 {
+		t.Errorf("unsafe detector ran %d calls at once, want 1", peak)
+	}
+
+	safe := &countingDetector{safe: true}
+	if _, err := (&Scanner{Detectors: []detection.Detector{safe}, Jobs: 8}).ScanCommitRange(dir, ""); err != nil {
+		t.Fatalf("scan: %v", err)
+	}
+	if peak := safe.peak.Load(); peak < 2 {
+		t.Errorf("safe detector peak concurrency = %d, want more than 1", peak)
+	}
+}
+
+func TestScannerParallelStopsEarly(t *testing.T) {
+	dir := initLongRepo(t, 20)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		n := 0
+		for _, err := range (&Scanner{Detectors: allDetectors(), Jobs: 4}).Stream(dir, "") {
+			if err != nil {
+				t.Errorf("Stream: %v", err)
+				return
+			}
+			n++
+			if n == 3 {
+				break
+			}
+		}
+	}()
+
+	finished := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(10 * time.Second):
+		t.Fatal("parallel stream did not stop after the caller broke out")
+	}
+}
+
+func TestScannerParallelError(t *testing.T) {
+	_, err := (&Scanner{Detectors: allDetectors(), Jobs: 4}).ScanCommitRange(t.TempDir(), "")
+	if err == nil {
+		t.Error("expected error for non-repo directory")
+	}
+}
@@ -0,0 +1,11 @@
+// Package schema publishes the JSON Schema of the scan report, so that
+// consumers can validate saved reports and generate readers from it.
+package schema
+
+import _ "embed"
+
+// Report is the JSON Schema, draft 2020-12, of the report that scan
+// --format=json writes: a scan.Report of scan.ReportSchemaVersion.
+//
+//go:embed report.schema.json
+var Report []byte
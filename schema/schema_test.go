@@ -0,0 +1,116 @@
+package schema
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/chaoss/ai-detection-action/detection"
+	"github.com/chaoss/ai-detection-action/scan"
+)
+
+type node struct {
+	Ref        string           `json:"$ref"`
+	Const      *int             `json:"const"`
+	Required   []string         `json:"required"`
+	Properties map[string]*node `json:"properties"`
+	Items      *node            `json:"items"`
+	Defs       map[string]*node `json:"$defs"`
+}
+
+func loadSchema(t *testing.T) *node {
+	t.Helper()
+	var root node
+	if err := json.Unmarshal(Report, &root); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+	return &root
+}
+
+// resolve follows a local "#/$defs/..." reference.
+func resolve(t *testing.T, root, n *node) *node {
+	t.Helper()
+	if n.Ref == "" {
+		return n
+	}
+	def, ok := root.Defs[strings.TrimPrefix(n.Ref, "#/$defs/")]
+	if !ok {
+		t.Fatalf("unresolved reference %s", n.Ref)
+	}
+	return def
+}
+
+// jsonFields returns the JSON names of typ's fields, flattening embedded
+// structs, and which of them are always written.
+func jsonFields(typ reflect.Type) (names, required []string) {
+	for _, f := range reflect.VisibleFields(typ) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		names = append(names, name)
+		if opts != "omitempty" {
+			required = append(required, name)
+		}
+	}
+	slices.Sort(names)
+	slices.Sort(required)
+	return names, required
+}
+
+func TestSchemaVersion(t *testing.T) {
+	root := loadSchema(t)
+	v := root.Properties["schema_version"]
+	if v == nil || v.Const == nil || *v.Const != scan.ReportSchemaVersion {
+		t.Errorf("schema_version const does not match scan.ReportSchemaVersion %d", scan.ReportSchemaVersion)
+	}
+}
+
+// TestSchemaMatchesTypes checks that the schema describes every field of the
+// report types, and requires exactly those always written.
+func TestSchemaMatchesTypes(t *testing.T) {
+	root := loadSchema(t)
+	commit := resolve(t, root, root.Properties["commits"].Items)
+	finding := resolve(t, root, commit.Properties["findings"].Items)
+	fileLines := resolve(t, root, finding.Properties["lines"].Items)
+	meta := resolve(t, root, root.Properties["metadata"])
+
+	for _, tc := range []struct {
+		name string
+		node *node
+		typ  any
+	}{
+		{"report", root, scan.Report{}},
+		{"commit", commit, scan.CommitResult{}},
+		{"signature", resolve(t, root, commit.Properties["author"]), scan.Signature{}},
+		{"finding", finding, detection.Finding{}},
+		{"fileLines", fileLines, detection.FileLines{}},
+		{"lineRange", fileLines.Properties["ranges"].Items, detection.LineRange{}},
+		{"summary", resolve(t, root, root.Properties["summary"]), scan.Summary{}},
+		{"metadata", meta, scan.Metadata{}},
+		{"source", resolve(t, root, root.Properties["sources"].Items), scan.Metadata{}},
+		{"repository", meta.Properties["repository"], scan.Repository{}},
+		{"range", meta.Properties["range"], scan.Range{}},
+		{"detectors", meta.Properties["detectors"], scan.Detectors{}},
+		{"filters", meta.Properties["filters"], scan.Filters{}},
+	} {
+		names, required := jsonFields(reflect.TypeOf(tc.typ))
+		var props []string
+		for name := range tc.node.Properties {
+			props = append(props, name)
+		}
+		slices.Sort(props)
+		if !slices.Equal(props, names) {
+			t.Errorf("%s: schema properties %v, want %v", tc.name, props, names)
+		}
+		got := slices.Sorted(slices.Values(tc.node.Required))
+		if !slices.Equal(got, required) {
+			t.Errorf("%s: schema requires %v, want %v", tc.name, got, required)
+		}
+	}
+}